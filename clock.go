@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"time"
+)
+
+/**
+ * Simulated clock of the backfill mode: the payments start at the backfill rate (Poisson arrivals) between
+ * the start and the end of the window, and the bank updates are produced every UPDATE_BANKS_INTERVAL of simulated time.
+ * Not safe for concurrent use, the same seed generates the same timestamps.
+ */
+type simulatedClock struct {
+	now      time.Time
+	end      time.Time
+	rate     float64
+	rng      *rand.Rand
+	banks    *bankUpdater
+	interval time.Duration
+	nextBank time.Time
+}
+
+func newSimulatedClock(cfg config.BackfillConfig, interval time.Duration) *simulatedClock {
+	return &simulatedClock{
+		now:      cfg.Start,
+		end:      cfg.End,
+		rate:     cfg.Rate,
+		rng:      rand.New(rand.NewSource(seed + 4)),
+		banks:    newBankUpdater(),
+		interval: interval,
+		nextBank: cfg.Start.Add(interval),
+	}
+}
+
+// Advances the clock to the start of the next payment, false at the end of the window
+func (c *simulatedClock) next(ctx context.Context) (time.Time, bool) {
+	c.now = c.now.Add(time.Duration(c.rng.ExpFloat64() / c.rate * float64(time.Second)))
+	if !c.now.Before(c.end) {
+		c.updateBanks(ctx, c.end)
+		return c.end, false
+	}
+	c.updateBanks(ctx, c.now)
+	return c.now, true
+}
+
+// Produces the bank updates due before ts
+func (c *simulatedClock) updateBanks(ctx context.Context, ts time.Time) {
+	for c.nextBank.Before(ts) && ctx.Err() == nil {
+		c.banks.update(ctx, c.nextBank)
+		c.nextBank = c.nextBank.Add(c.interval)
+	}
+}
@@ -1,149 +1,229 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"math/rand"
 	model "mcolomerc/synth-payment-producer/pkg/avro"
 	"mcolomerc/synth-payment-producer/pkg/config"
 	"mcolomerc/synth-payment-producer/pkg/datagen"
 	"mcolomerc/synth-payment-producer/pkg/producer"
 	"mcolomerc/synth-payment-producer/pkg/stats"
+	"os"
+	"os/signal"
 	"runtime"
 	"strconv"
-	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/m-mizutani/zlog"
 	"github.com/m-mizutani/zlog/filter"
 	"github.com/mackerelio/go-osstat/cpu"
+	"golang.org/x/time/rate"
 )
 
 var cnf config.Config
-var wkf datagen.Workflow
 
 var numPayments int
-var paymentsCh chan model.Payment
 var workers int
 
-var kProd producer.Producer
+var sink producer.Sink
 var paymentGenerator datagen.Datagen
 var workflowHandler datagen.Workflow
+var delays datagen.Delays
 
 var logger *zlog.Logger
 
+var scenarioFile string
+
 var sts *stats.Stats
 
+var seed int64
+
+// Central rate limiters, nil means unlimited
+var paymentsLimiter *rate.Limiter
+var eventsLimiter *rate.Limiter
+
+// Payment with the workflow picked when it was generated, and the sampled delay of each status
+type job struct {
+	payment  model.Payment
+	workflow []datagen.Status
+	delays   []time.Duration
+	start    time.Time // Simulated start in backfill mode, zero with the real clock
+}
+
 func init() {
 	logger = zlog.New(zlog.WithFilters(filter.Tag()))
 
-	// Read config from env vars
-	cnf = config.Build()
+	flag.StringVar(&scenarioFile, "scenario", "", "YAML or JSON scenario file (or SCENARIO_FILE env var)")
+	flag.Parse()
+
+	// Read config from scenario file and env vars
+	cnf = config.Build(scenarioFile)
+	logger = cnf.Logger()
+	if err := cnf.Validate(datagen.GetStatusNames()); err != nil {
+		logger.Error("%s", err)
+		os.Exit(1)
+	}
 	numPayments = cnf.Datagen.Payments
 	workers = cnf.Datagen.Workers
 
-	kProd = producer.NewProducer(cnf)
-	paymentGenerator = datagen.NewDatagen(cnf.Datagen.Sources, cnf.Datagen.Destinations)
-	workflowHandler = datagen.NewWorkflowHandler(cnf)
+	seed = datagen.Seed(cnf.Datagen.Seed)
+	logger.Info("Using seed: %v", seed)
+
+	var err error
+	workflowHandler, err = datagen.NewWorkflowHandler(cnf, seed+1)
+	if err != nil {
+		logger.Error("Invalid workflows: %s", err)
+		os.Exit(1)
+	}
+	delays, err = datagen.NewDelays(cnf, seed+3)
+	if err != nil {
+		logger.Error("Invalid delays: %s", err)
+		os.Exit(1)
+	}
 	sts = stats.NewStats()
+	sink, err = producer.NewProducer(cnf, sts)
+	if err != nil {
+		logger.Error("Failed to create the %s sink: %s", cnf.Sink.Type, err)
+		os.Exit(1)
+	}
+	paymentGenerator = datagen.NewDatagen(cnf.Datagen.Sources, cnf.Datagen.Destinations, seed)
+	if cnf.Datagen.Mode == config.ModeBackfill {
+		paymentGenerator.SetCreated(cnf.Datagen.Backfill.Start)
+	}
+	if b, ok := sink.(producer.BankAware); ok {
+		b.SetBanks(paymentGenerator.GetBanks())
+	}
+
+	paymentsLimiter = newLimiter(cnf.Datagen.PaymentsRate)
+	eventsLimiter = newLimiter(cnf.Datagen.EventsRate)
+	sts.SetTargetRate("Payments", cnf.Datagen.PaymentsRate)
+	sts.SetTargetRate("Events", cnf.Datagen.EventsRate)
 }
 
 func main() {
+	os.Exit(run())
+}
+
+// Runs the generation, returns the exit code
+func run() int {
 	for _, st := range datagen.GetStatusList() {
 		sts.AddState(st.String())
 	}
 	logger.Info("Starting producer...")
-	logger.With("bootstrap.server", cnf.Kafka.BootstrapServers).Info("Using: ")
-	numPayments := cnf.Datagen.Payments
+	logger.With("sink", cnf.Sink.Type).Info("Using: ")
 	message := fmt.Sprintf("Generating... [%v] payments", numPayments)
+	switch cnf.Datagen.Mode {
+	case config.ModeContinuous:
+		message = "Generating... payments until stopped"
+	case config.ModeDuration:
+		message = fmt.Sprintf("Generating... payments for %v", cnf.Datagen.Duration)
+	case config.ModeBackfill:
+		message = fmt.Sprintf("Generating... payments from %v to %v", cnf.Datagen.Backfill.Start.Format(time.RFC3339), cnf.Datagen.Backfill.End.Format(time.RFC3339))
+	}
 	defer timer(message)()
 
 	// Create topics
 	logger.With("Topics", cnf.Kafka.Topics).Info("Topics: ")
-	kProd.CreateTopics() // Create topics
+	if err := sink.CreateTopics(); err != nil { // Create topics
+		logger.Error("%s", err)
+		return 1
+	}
+
+	// Stops on SIGINT/SIGTERM or abort, a second signal kills the process
+	var ctx, inflight context.Context
+	ctx, cancelRun = context.WithCancel(context.Background())
+	defer cancelRun()
+	// In-flight workflows are abandoned on shutdown or drained (completed), always abandoned on abort
+	inflight, cancelInflight = context.WithCancel(context.Background())
+	defer cancelInflight()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		signal.Stop(signals)
+		logger.Info("## %v: shutting down (%v), stops generating payments ##", sig, cnf.Shutdown.Mode)
+		cancelRun()
+		if cnf.Shutdown.Mode == config.ShutdownAbandon {
+			cancelInflight()
+		}
+	}()
 
 	// Generate banks
-	stop := make(chan bool, 1)
+	banksCtx, stopBanks := context.WithCancel(ctx)
 	interval := time.Duration(cnf.Datagen.UpdateBanksInterval)
-	go buildBanks(time.NewTicker(interval*time.Millisecond), stop)
-	// Generate payments
-	paymentsCh := make(chan model.Payment, numPayments)
-	for i := 0; i < numPayments; i++ {
-		logger.Info(" Generating payment...%v", i)
-		payment := paymentGenerator.GeneratePayment() // Generate payment
-		paymentsCh <- payment
+	if cnf.Datagen.Mode != config.ModeBackfill { // Produced with the simulated clock in backfill mode
+		go buildBanks(banksCtx, time.NewTicker(interval*time.Millisecond))
+	}
+	// Generate payments: lazily, the buffer bounds the payments waiting for a worker
+	size := cnf.Datagen.Buffer
+	if size == 0 {
+		size = workers
+	}
+	paymentsCh := make(chan job, size)
+	// The payments are generated and started until the deadline in duration mode, the in-flight workflows drain
+	generation := ctx
+	if cnf.Datagen.Mode == config.ModeDuration {
+		var cancel context.CancelFunc
+		generation, cancel = context.WithTimeout(ctx, cnf.Datagen.Duration)
+		defer cancel()
+	}
+	go generate(generation, paymentsCh)
+
+	if cnf.Schema.SwitchAfter > 0 { // Schema evolution after a delay, see generate for the payments count
+		switchTimer := time.AfterFunc(cnf.Schema.SwitchAfter, func() {
+			switchSchema(fmt.Sprintf("%v elapsed", cnf.Schema.SwitchAfter))
+		})
+		defer switchTimer.Stop()
 	}
-	done := make(chan string, numPayments)
-	workers := cnf.Datagen.Workers
 
 	logger.Info(" Using workers: %v", workers)
+	sts.Start()
+	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ { // Spawn workers
-		go worker(i, paymentsCh, done)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			worker(generation, inflight, i, paymentsCh)
+		}(i)
 	}
-	for i := 0; i < numPayments; i++ {
-		workflow := <-done
-		sts.AddWorkflow(workflow) // Add workflow
+	wg.Wait() // Until the payments channel is closed and drained, the deadline or shutdown
+	if cnf.Datagen.Mode == config.ModeDuration && ctx.Err() == nil {
+		logger.Info("## Duration %v elapsed ##", cnf.Datagen.Duration)
 	}
+	sts.Stop()
 	logger.Info("## Stops the bank updater ##")
-	stop <- true
-	// Close Producer
-	kProd.Flush()
-	kProd.Close()
+	stopBanks()
+	// Close Producer, flush with a deadline on shutdown
+	flushCtx := context.Background()
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		flushCtx, cancel = context.WithTimeout(flushCtx, cnf.Shutdown.Timeout)
+		defer cancel()
+	}
+	code := 0
+	if err := sink.Flush(flushCtx); err != nil {
+		logger.Error("Flush: %s", err)
+		code = 1
+	}
+	sink.Close()
 	// Print stats
 	sts.Print()
-}
-
-func buildBanks(ticker *time.Ticker, done <-chan bool) {
-	logger.Info("## BANKS ## Generating banks")
-	banks := paymentGenerator.GetBanks() // Get banks
-	for {
-		select {
-		case <-ticker.C:
-			logger.Info("## BANKS ## Updating bank ...")
-			source := rand.NewSource(time.Now().UnixNano())
-			rng := rand.New(source)
-			randIdex := rng.Intn(len(banks))
-			bank := banks[randIdex]
-			bank.Updated_ts = time.Now().Format(time.RFC3339)
-			bank.Version += 1
-			banks[randIdex] = bank
-			logger.Info(" Bank: %v", bank)
-			kProd.ProduceBank(bank)
-			sts.AddBank(bank.Name)
-		case <-done:
-			logger.Info("## BANKS ## Done")
-			return
-		}
+	if failures := sts.DeliveryFailures(); failures > 0 {
+		logger.Error("%v events failed to be delivered", failures)
+		code = 1
 	}
-}
-
-/**
- * Worker
- */
-func worker(w int, paymentsCh <-chan model.Payment, done chan<- string) {
-	for payment := range paymentsCh {
-		wk := workflowHandler.GetWorkflow()
-		logger.Info(" Worker-%v : Producing payment: %v : Workflow: %v", w, payment, wk)
-		// Get workflow status
-		statusDone := make(chan model.Payment, len(wk))
-		for i := range wk {
-			go func(i int, payment model.Payment) {
-				payment.Status = wk[i].String()
-				delay := cnf.Datagen.Delays[strings.ToLower(payment.Status)] // Get delay by status
-				time.Sleep(time.Duration(delay) * time.Millisecond)          // Apply delay
-				payment.Ts = time.Now().UTC().UnixNano() / 1000000
-				payment.Date_ts = time.Now().Format(time.RFC3339)
-				logger.Info("\t Worker-%v : Producing payment status update: %v ", w, payment)
-				kProd.Produce(payment)
-				statusDone <- payment
-			}(i, payment)
-		}
-		for i := 0; i < len(wk); i++ {
-			payment := <-statusDone
-			sts.IncState(payment.Status) // Increment state counter
-		}
-		close(statusDone)
-		done <- fmt.Sprintf("%v", wk)
+	if skipped := sts.Errors(); skipped > 0 {
+		logger.Error("%v events skipped or aborted because of sink errors", skipped)
+		code = 1
+	}
+	if err := aborted(); err != nil {
+		logger.Error("Aborted: %s", err)
+		code = 1
 	}
+	return code
 }
 
 func timer(name string) func() {
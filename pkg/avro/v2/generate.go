@@ -0,0 +1,4 @@
+package v2
+
+//go:generate $GOPATH/bin/gogen-avro -package v2 . ../../../avro/payment_v2.avsc
+//go:generate $GOPATH/bin/gogen-avro -package v2 . ../../../avro/bank_v2.avsc
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/m-mizutani/zlog"
@@ -15,24 +17,168 @@ type Config struct {
 	Kafka          KafkaConfig          `mapstructure:"kafka" `
 	SchemaRegistry SchemaRegistryConfig `mapstructure:"schemaRegistry"`
 	Datagen        Datagen              `mapstructure:"datagen"`
+	Sink           SinkConfig           `mapstructure:"sink"`
+	Schema         SchemaConfig         `mapstructure:"schema"`
+	Shutdown       ShutdownConfig       `mapstructure:"shutdown"`
+	Errors         ErrorsConfig         `mapstructure:"errors"`
+
+	envErrors []string // invalid environment variables, reported by Validate
+}
+
+type ErrorsConfig struct {
+	Policy  string        `mapstructure:"policy"`
+	Retries int           `mapstructure:"retries"`
+	Backoff time.Duration `mapstructure:"backoff"`
+}
+
+// Error policies, for the errors returned by the sink
+const (
+	ErrorRetry = "retry" // Retries the retriable errors (e.g. queue full) with backoff, then skips
+	ErrorSkip  = "skip"  // Skips the event and counts it
+	ErrorAbort = "abort" // Stops the run
+)
+
+// Schema evolution: the events use the Version schema, then the SwitchTo schema
+// once SwitchAfterPayments payments are generated or SwitchAfter is elapsed
+type SchemaConfig struct {
+	Version             int           `mapstructure:"version"`  // 1 (avro/payment.avsc) or 2 (avro/payment_v2.avsc)
+	SwitchTo            int           `mapstructure:"switchTo"` // 0 means no switch
+	SwitchAfterPayments int           `mapstructure:"switchAfterPayments"`
+	SwitchAfter         time.Duration `mapstructure:"switchAfter"`
+}
+
+type ShutdownConfig struct {
+	Mode    string        `mapstructure:"mode"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Shutdown modes, for the in-flight workflows on SIGINT/SIGTERM
+const (
+	ShutdownDrain   = "drain"   // Completes the in-flight workflows
+	ShutdownAbandon = "abandon" // Drops the pending status updates
+)
+
+type SinkConfig struct {
+	Type    string            `mapstructure:"type"`
+	File    FileConfig        `mapstructure:"file"`
+	Avro    AvroFileConfig    `mapstructure:"avro"`
+	Parquet ParquetFileConfig `mapstructure:"parquet"`
+	Csv     CsvFileConfig     `mapstructure:"csv"`
+	Console ConsoleConfig     `mapstructure:"console"`
+	Webhook WebhookConfig     `mapstructure:"webhook"`
+}
+
+// File sinks configuration
+type FileConfig struct {
+	Dir      string `mapstructure:"dir"`
+	Single   bool   `mapstructure:"single"`   // One file for all the topics
+	Gzip     bool   `mapstructure:"gzip"`     // Compress the files
+	MaxBytes int64  `mapstructure:"maxBytes"` // Rotates the files, 0 means no rotation
+}
+
+// Avro Object Container File sink configuration
+type AvroFileConfig struct {
+	Codec        string `mapstructure:"codec"`        // null, deflate or snappy
+	BlockRecords int64  `mapstructure:"blockRecords"` // Records per block
+}
+
+// Parquet sink configuration
+type ParquetFileConfig struct {
+	Compression  string `mapstructure:"compression"`  // none, snappy, gzip or zstd
+	RowGroupRows int64  `mapstructure:"rowGroupRows"` // Rows per row group
+}
+
+// CSV sink configuration
+type CsvFileConfig struct {
+	Delimiter string   `mapstructure:"delimiter"` // Single character or "tab"
+	Columns   []string `mapstructure:"columns"`
+}
+
+// Console sink configuration
+type ConsoleConfig struct {
+	Format string `mapstructure:"format"` // line or json
+	Color  bool   `mapstructure:"color"`  // Colors the lines by status
+}
+
+// Webhook sink configuration
+type WebhookConfig struct {
+	Url         string            `mapstructure:"url"` // {status} and {topic} are replaced
+	Concurrency int               `mapstructure:"concurrency"`
+	Timeout     time.Duration     `mapstructure:"timeout"` // Per request
+	Retries     int               `mapstructure:"retries"` // On 5xx, 429 and connection errors
+	Backoff     time.Duration     `mapstructure:"backoff"` // Initial backoff, doubled on each retry
+	Headers     map[string]string `mapstructure:"headers"`
+	Secret      string            `mapstructure:"secret" zlog:"secret"` // HMAC-SHA256 signing key, optional
 }
 
 type Datagen struct {
-	Payments            int            `mapstructure:"payments"`
-	Workers             int            `mapstructure:"workers"`
-	Sources             int            `mapstructure:"sources"`
-	Destinations        int            `mapstructure:"destinations"`
-	Workflows           map[string]int `mapstructure:"workflows"`
-	Delays              map[string]int `mapstructure:"delays"`
-	UpdateBanksInterval int            `mapstructure:"updateBanksInterval"`
+	Payments            int                               `mapstructure:"payments"`
+	Workers             int                               `mapstructure:"workers"`
+	Sources             int                               `mapstructure:"sources"`
+	Destinations        int                               `mapstructure:"destinations"`
+	Model               string                            `mapstructure:"model"` // workflows or graph
+	Workflows           map[string]int                    `mapstructure:"workflows"`
+	Graph               GraphConfig                       `mapstructure:"graph"`
+	Delays              map[string]DelayConfig            `mapstructure:"delays"`         // status or transition (from->to): delay
+	WorkflowDelays      map[string]map[string]DelayConfig `mapstructure:"workflowDelays"` // workflow: {status or transition: delay}, overrides the delays
+	UpdateBanksInterval int                               `mapstructure:"updateBanksInterval"`
+	Sequential          bool                              `mapstructure:"sequential"`
+	Seed                int64                             `mapstructure:"seed"`
+	PaymentsRate        float64                           `mapstructure:"paymentsRate"`
+	EventsRate          float64                           `mapstructure:"eventsRate"`
+	Mode                string                            `mapstructure:"mode"`
+	Duration            time.Duration                     `mapstructure:"duration"`
+	Buffer              int                               `mapstructure:"buffer"`
+	Backfill            BackfillConfig                    `mapstructure:"backfill"`
+}
+
+// Run modes
+const (
+	ModeCount      = "count"      // Generates Payments and stops
+	ModeContinuous = "continuous" // Generates payments until stopped
+	ModeDuration   = "duration"   // Generates payments during Duration
+	ModeBackfill   = "backfill"   // Generates the payments of the Backfill window with a simulated clock
+)
+
+// Backfill mode: the payments start at Rate per second of simulated time between Start and End,
+// the events are timestamped with the simulated clock and produced without delays
+type BackfillConfig struct {
+	Start time.Time `mapstructure:"start"` // RFC3339
+	End   time.Time `mapstructure:"end"`
+	Rate  float64   `mapstructure:"rate"` // Payments per simulated second
+}
+
+// Workflow models
+const (
+	ModelWorkflows = "workflows" // Picks one of the weighted Workflows
+	ModelGraph     = "graph"     // Walks the Graph transitions from the Start status to a Terminal status
+)
+
+// Transition graph (Markov chain) of the graph workflow model
+type GraphConfig struct {
+	Start       string                        `mapstructure:"start"`
+	Terminal    []string                      `mapstructure:"terminal"`
+	Transitions map[string]map[string]float64 `mapstructure:"transitions"` // from: {to: probability}
+	MaxSteps    int                           `mapstructure:"maxSteps"`    // Bounds the walk, the loops may repeat
 }
 
 type SchemaRegistryConfig struct {
 	Endpoint  string `mapstructure:"endpoint"`
 	ApiKey    string `mapstructure:"key"`
 	ApiSecret string `mapstructure:"secret" zlog:"secret"`
+	// Subject of the schemas: topic (<topic>-value), record (<record>) or topicrecord (<topic>-<record>)
+	SubjectNameStrategy string `mapstructure:"subjectNameStrategy"`
+	AutoRegister        bool   `mapstructure:"autoRegister"`     // Registers the schemas
+	UseLatestVersion    bool   `mapstructure:"useLatestVersion"` // Uses the latest registered version, without auto-registration
 }
 
+// Schema Registry subject naming strategies
+const (
+	SubjectTopicName       = "topic"
+	SubjectRecordName      = "record"
+	SubjectTopicRecordName = "topicrecord"
+)
+
 type KafkaConfig struct {
 	BootstrapServers string            `mapstructure:"bootstrapServers"`
 	ClientId         string            `mapstructure:"clientId"`
@@ -42,21 +188,143 @@ type KafkaConfig struct {
 	SaslPassword     string            `mapstructure:"saslPassword" zlog:"secret"`
 	ConfigMap        map[string]string `mapstructure:"configMap"`
 	Topics           map[string]int    `mapstructure:"topics"`
+	Serialization    string            `mapstructure:"serialization"` // avro, jsonschema, protobuf or json
 }
 
-func Build() Config {
+// Kafka message value serializations
+const (
+	SerializationAvro       = "avro"       // Schema Registry Avro
+	SerializationJsonSchema = "jsonschema" // Schema Registry JSON Schema
+	SerializationProtobuf   = "protobuf"   // Schema Registry Protobuf
+	SerializationJson       = "json"       // Schemaless JSON, no Schema Registry
+)
 
-	logger := zlog.New(zlog.WithFilters(filter.Tag()))
+// Build loads the configuration: defaults, then the scenario file (if any), then the environment variables
+func Build(scenarioFile string) Config {
 
 	err := godotenv.Load() // 👈 load .env file
 	if err != nil {
 		log.Println(err)
 	}
 
-	config := Config{} // Init config
-	config.Kafka.BootstrapServers = getenv("KAFKA_BOOTSTRAP_SERVER", "localhost:9092")
-	config.Kafka.SaslUsername = getenv("KAFKA_SASL_USERNAME", "admin")
-	config.Kafka.SaslPassword = getenv("KAFKA_SASL_PASSWORD", "admin-secret")
+	config := defaults() // Init config
+	envErrors = nil
+
+	scenarioFile = getenv("SCENARIO_FILE", scenarioFile)
+	if len(scenarioFile) > 0 {
+		if err := LoadScenario(scenarioFile, &config); err != nil {
+			log.Fatalf("Failed to load scenario file %s: %s", scenarioFile, err)
+		}
+	}
+
+	// Environment variables override the scenario
+	config.Kafka.BootstrapServers = getenv("KAFKA_BOOTSTRAP_SERVER", config.Kafka.BootstrapServers)
+	config.Kafka.SaslUsername = getenv("KAFKA_SASL_USERNAME", config.Kafka.SaslUsername)
+	config.Kafka.SaslPassword = getenv("KAFKA_SASL_PASSWORD", config.Kafka.SaslPassword)
+	config.Kafka.ClientId = getenv("KAFKA_CLIENT_ID", config.Kafka.ClientId)
+	config.Kafka.SaslMechanisms = getenv("KAFKA_SASL_MECHANISMS", config.Kafka.SaslMechanisms)
+	config.Kafka.SecurityProtocol = getenv("KAFKA_SECURITY_PROTOCOL", config.Kafka.SecurityProtocol)
+	config.Kafka.Serialization = strings.ToLower(getenv("KAFKA_SERIALIZATION", config.Kafka.Serialization))
+
+	config.Sink.Type = getenv("SINK_TYPE", config.Sink.Type)
+	config.Sink.File.Dir = getenv("SINK_FILE_DIR", config.Sink.File.Dir)
+	config.Sink.File.Single = getenvBool("SINK_FILE_SINGLE", config.Sink.File.Single)
+	config.Sink.File.Gzip = getenvBool("SINK_FILE_GZIP", config.Sink.File.Gzip)
+	config.Sink.File.MaxBytes = int64(getenvInt("SINK_FILE_MAX_BYTES", int(config.Sink.File.MaxBytes)))
+	config.Sink.Avro.Codec = getenv("SINK_AVRO_CODEC", config.Sink.Avro.Codec)
+	config.Sink.Avro.BlockRecords = int64(getenvInt("SINK_AVRO_BLOCK_RECORDS", int(config.Sink.Avro.BlockRecords)))
+	config.Sink.Parquet.Compression = getenv("SINK_PARQUET_COMPRESSION", config.Sink.Parquet.Compression)
+	config.Sink.Parquet.RowGroupRows = int64(getenvInt("SINK_PARQUET_ROW_GROUP_ROWS", int(config.Sink.Parquet.RowGroupRows)))
+	config.Sink.Csv.Delimiter = getenv("SINK_CSV_DELIMITER", config.Sink.Csv.Delimiter)
+	if columns, ok := os.LookupEnv("SINK_CSV_COLUMNS"); ok {
+		config.Sink.Csv.Columns = strings.Split(columns, ",")
+	}
+	config.Sink.Console.Format = getenv("SINK_CONSOLE_FORMAT", config.Sink.Console.Format)
+	config.Sink.Console.Color = getenvBool("SINK_CONSOLE_COLOR", config.Sink.Console.Color)
+	config.Sink.Webhook.Url = getenv("SINK_WEBHOOK_URL", config.Sink.Webhook.Url)
+	config.Sink.Webhook.Concurrency = getenvInt("SINK_WEBHOOK_CONCURRENCY", config.Sink.Webhook.Concurrency)
+	config.Sink.Webhook.Timeout = getenvDuration("SINK_WEBHOOK_TIMEOUT", config.Sink.Webhook.Timeout)
+	config.Sink.Webhook.Retries = getenvInt("SINK_WEBHOOK_RETRIES", config.Sink.Webhook.Retries)
+	config.Sink.Webhook.Backoff = getenvDuration("SINK_WEBHOOK_BACKOFF", config.Sink.Webhook.Backoff)
+	config.Sink.Webhook.Headers = getenvMap("SINK_WEBHOOK_HEADERS", config.Sink.Webhook.Headers)
+	config.Sink.Webhook.Secret = getenv("SINK_WEBHOOK_SECRET", config.Sink.Webhook.Secret)
+
+	config.Errors.Policy = strings.ToLower(getenv("ERROR_POLICY", config.Errors.Policy))
+	config.Errors.Retries = getenvInt("ERROR_RETRIES", config.Errors.Retries)
+	config.Errors.Backoff = getenvDuration("ERROR_BACKOFF", config.Errors.Backoff)
+
+	config.Schema.Version = getenvInt("SCHEMA_VERSION", config.Schema.Version)
+	config.Schema.SwitchTo = getenvInt("SCHEMA_SWITCH_TO", config.Schema.SwitchTo)
+	config.Schema.SwitchAfterPayments = getenvInt("SCHEMA_SWITCH_AFTER_PAYMENTS", config.Schema.SwitchAfterPayments)
+	config.Schema.SwitchAfter = getenvDuration("SCHEMA_SWITCH_AFTER", config.Schema.SwitchAfter)
+
+	config.Shutdown.Mode = strings.ToLower(getenv("SHUTDOWN_MODE", config.Shutdown.Mode))
+	config.Shutdown.Timeout = getenvDuration("SHUTDOWN_TIMEOUT", config.Shutdown.Timeout)
+
+	config.SchemaRegistry.Endpoint = getenv("SCHEMA_REGISTRY_ENDPOINT", config.SchemaRegistry.Endpoint)
+	config.SchemaRegistry.ApiKey = getenv("SCHEMA_REGISTRY_API_KEY", config.SchemaRegistry.ApiKey)
+	config.SchemaRegistry.ApiSecret = getenv("SCHEMA_REGISTRY_API_SECRET", config.SchemaRegistry.ApiSecret)
+	config.SchemaRegistry.SubjectNameStrategy = strings.ToLower(getenv("SCHEMA_REGISTRY_SUBJECT_NAME_STRATEGY", config.SchemaRegistry.SubjectNameStrategy))
+	config.SchemaRegistry.AutoRegister = getenvBool("SCHEMA_REGISTRY_AUTO_REGISTER", config.SchemaRegistry.AutoRegister)
+	config.SchemaRegistry.UseLatestVersion = getenvBool("SCHEMA_REGISTRY_USE_LATEST_VERSION", config.SchemaRegistry.UseLatestVersion)
+
+	config.Datagen.Payments = getenvInt("NUM_PAYMENTS", config.Datagen.Payments)
+	config.Datagen.Workers = getenvInt("NUM_WORKERS", config.Datagen.Workers)
+	config.Datagen.Sources = getenvInt("NUM_SOURCES", config.Datagen.Sources)
+	config.Datagen.Destinations = getenvInt("NUM_DESTINATIONS", config.Datagen.Destinations)
+	config.Datagen.UpdateBanksInterval = getenvInt("UPDATE_BANKS_INTERVAL", config.Datagen.UpdateBanksInterval)
+	config.Datagen.Sequential = getenvBool("SEQUENTIAL_WORKFLOW", config.Datagen.Sequential)
+	config.Datagen.Seed = int64(getenvInt("SEED", int(config.Datagen.Seed)))
+	config.Datagen.PaymentsRate = getenvFloat("PAYMENTS_RATE", config.Datagen.PaymentsRate)
+	config.Datagen.EventsRate = getenvFloat("EVENTS_RATE", config.Datagen.EventsRate)
+	config.Datagen.Mode = strings.ToLower(getenv("RUN_MODE", config.Datagen.Mode))
+	config.Datagen.Duration = getenvDuration("DURATION", config.Datagen.Duration)
+	config.Datagen.Buffer = getenvInt("PAYMENTS_BUFFER", config.Datagen.Buffer)
+	config.Datagen.Model = strings.ToLower(getenv("WORKFLOW_MODEL", config.Datagen.Model))
+	config.Datagen.Backfill.Start = getenvTime("BACKFILL_START", config.Datagen.Backfill.Start)
+	config.Datagen.Backfill.End = getenvTime("BACKFILL_END", config.Datagen.Backfill.End)
+	config.Datagen.Backfill.Rate = getenvFloat("BACKFILL_RATE", config.Datagen.Backfill.Rate)
+
+	statuses := []string{"canceled", "completed", "failed", "rejected", "validated", "accounted", "initiated", "pending"}
+	for _, status := range statuses {
+		key := "DELAY_" + strings.ToUpper(status)
+		if _, ok := os.LookupEnv(key); ok {
+			config.Datagen.Delays[status] = getenvDelay(key, config.Datagen.Delays[status])
+		}
+		// Transition delays, DELAY_<FROM>_<TO>
+		for _, to := range statuses {
+			key := "DELAY_" + strings.ToUpper(status) + "_" + strings.ToUpper(to)
+			if _, ok := os.LookupEnv(key); ok {
+				transition := TransitionKey(status, to)
+				config.Datagen.Delays[transition] = getenvDelay(key, config.Datagen.Delays[transition])
+			}
+		}
+	}
+
+	config.envErrors = envErrors
+
+	logger := config.Logger()
+	if len(scenarioFile) > 0 {
+		logger.Info("Loaded scenario file: %s", scenarioFile)
+	}
+	logger.With("Config", config).Info("Configuration:")
+	return config
+}
+
+// Logger returns a new logger, writing to stderr with the console sink so that stdout only has the events
+func (c Config) Logger() *zlog.Logger {
+	options := []zlog.Option{zlog.WithFilters(filter.Tag())}
+	if strings.EqualFold(c.Sink.Type, "console") {
+		options = append(options, zlog.WithEmitter(zlog.NewConsoleEmitter(zlog.ConsoleWriter(os.Stderr))))
+	}
+	return zlog.New(options...)
+}
+
+func defaults() Config {
+	config := Config{}
+	config.Kafka.BootstrapServers = "localhost:9092"
+	config.Kafka.SaslUsername = "admin"
+	config.Kafka.SaslPassword = "admin-secret"
 	config.Kafka.ConfigMap = map[string]string{
 		"statistics.interval.ms":     "3000",
 		"compression.codec":          "lz4",
@@ -67,9 +335,10 @@ func Build() Config {
 		"queue.buffering.max.kbytes": "2048576",
 		"batch.num.messages":         "10000",
 	}
-	config.Kafka.ClientId = getenv("KAFKA_CLIENT_ID", "synthethic-payment-generator")
-	config.Kafka.SaslMechanisms = getenv("KAFKA_SASL_MECHANISMS", "PLAIN")
-	config.Kafka.SecurityProtocol = getenv("KAFKA_SECURITY_PROTOCOL", "SASL_SSL")
+	config.Kafka.ClientId = "synthethic-payment-generator"
+	config.Kafka.SaslMechanisms = "PLAIN"
+	config.Kafka.SecurityProtocol = "SASL_SSL"
+	config.Kafka.Serialization = SerializationAvro
 
 	config.Kafka.Topics = map[string]int{
 		"banks":             1,
@@ -80,17 +349,44 @@ func Build() Config {
 		"payment-validated": 12,
 		"payment-accounted": 12,
 		"payment-rejected":  4,
+		"payment-pending":   4,
 	}
 
-	config.SchemaRegistry.Endpoint = getenv("SCHEMA_REGISTRY_ENDPOINT", "http://localhost:8081")
-	config.SchemaRegistry.ApiKey = getenv("SCHEMA_REGISTRY_API_KEY", "")
-	config.SchemaRegistry.ApiSecret = getenv("SCHEMA_REGISTRY_API_SECRET", "")
+	config.Sink.Type = "kafka"
+	config.Sink.File.Dir = "output"
+	config.Sink.Avro.Codec = "deflate"
+	config.Sink.Avro.BlockRecords = 1000
+	config.Sink.Parquet.Compression = "snappy"
+	config.Sink.Parquet.RowGroupRows = 100000
+	config.Sink.Csv.Delimiter = ","
+	config.Sink.Csv.Columns = []string{"id", "ts", "date_ts", "status", "source", "destination", "currency", "amount"}
+	config.Sink.Console.Format = "line"
+	config.Sink.Console.Color = true
+	config.Sink.Webhook.Concurrency = 10
+	config.Sink.Webhook.Timeout = 5 * time.Second
+	config.Sink.Webhook.Retries = 3
+	config.Sink.Webhook.Backoff = 200 * time.Millisecond
+
+	config.Errors.Policy = ErrorRetry
+	config.Errors.Retries = 5
+	config.Errors.Backoff = 100 * time.Millisecond
+
+	config.Schema.Version = 1
 
-	config.Datagen.Payments = getenvInt("NUM_PAYMENTS", 100000)
-	config.Datagen.Workers = getenvInt("NUM_WORKERS", 100)
-	config.Datagen.Sources = getenvInt("NUM_SOURCES", 10)
-	config.Datagen.Destinations = getenvInt("NUM_DESTINATIONS", 10)
-	config.Datagen.UpdateBanksInterval = getenvInt("UPDATE_BANKS_INTERVAL", 3000)
+	config.Shutdown.Mode = ShutdownDrain
+	config.Shutdown.Timeout = 30 * time.Second
+
+	config.SchemaRegistry.Endpoint = "http://localhost:8081"
+	config.SchemaRegistry.SubjectNameStrategy = SubjectTopicName
+	config.SchemaRegistry.AutoRegister = true
+
+	config.Datagen.Payments = 100000
+	config.Datagen.Workers = 100
+	config.Datagen.Sources = 10
+	config.Datagen.Destinations = 10
+	config.Datagen.UpdateBanksInterval = 3000
+	config.Datagen.Mode = ModeCount
+	config.Datagen.Model = ModelWorkflows
 
 	config.Datagen.Workflows = map[string]int{
 		"Initiated, Failed":                          1,
@@ -103,20 +399,34 @@ func Build() Config {
 		"Initiated, Validated, Accounted, Rejected":  1,
 	}
 
-	config.Datagen.Delays = map[string]int{}
-
-	config.Datagen.Delays["canceled"] = getenvInt("DELAY_CANCELED", 2000)
-	config.Datagen.Delays["completed"] = getenvInt("DELAY_COMPLETED", 3000)
-	config.Datagen.Delays["failed"] = getenvInt("DELAY_FAILED", 1000)
-	config.Datagen.Delays["rejected"] = getenvInt("DELAY_REJECTED", 2000)
-	config.Datagen.Delays["validated"] = getenvInt("DELAY_VALIDATED", 1000)
-	config.Datagen.Delays["accounted"] = getenvInt("DELAY_ACCOUNTED", 1000)
-	config.Datagen.Delays["initiated"] = getenvInt("DELAY_INITIATED", 100)
+	config.Datagen.Delays = map[string]DelayConfig{
+		"canceled":  Constant(2000),
+		"completed": Constant(3000),
+		"failed":    Constant(1000),
+		"rejected":  Constant(2000),
+		"validated": Constant(1000),
+		"accounted": Constant(1000),
+		"initiated": Constant(100),
+		"pending":   Constant(2000),
+	}
 
-	logger.With("Config", config).Info("Configuration:")
+	config.Datagen.Graph = GraphConfig{
+		Start:    "Initiated",
+		Terminal: []string{"Completed", "Failed", "Rejected", "Canceled"},
+		Transitions: map[string]map[string]float64{
+			"Initiated": {"Validated": 0.85, "Rejected": 0.1, "Failed": 0.05},
+			"Validated": {"Accounted": 0.8, "Pending": 0.1, "Rejected": 0.05, "Failed": 0.05},
+			"Pending":   {"Validated": 1},
+			"Accounted": {"Completed": 0.85, "Canceled": 0.1, "Failed": 0.05},
+		},
+		MaxSteps: 20,
+	}
 	return config
 }
 
+// invalid environment variables found while building the config
+var envErrors []string
+
 func getenv(key, fallback string) string {
 	value := os.Getenv(key)
 	if len(value) == 0 {
@@ -132,8 +442,92 @@ func getenvInt(key string, fallback int) int {
 	}
 	value, err := strconv.Atoi(valueStr)
 	if err != nil {
-		// handle error
-		fmt.Println(err)
+		envErrors = append(envErrors, fmt.Sprintf("%s=%q is not an integer", key, valueStr))
+		return fallback
+	}
+	return value
+}
+
+func getenvBool(key string, fallback bool) bool {
+	valueStr := os.Getenv(key)
+	if len(valueStr) == 0 {
+		return fallback
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		envErrors = append(envErrors, fmt.Sprintf("%s=%q is not a boolean", key, valueStr))
+		return fallback
+	}
+	return value
+}
+
+func getenvFloat(key string, fallback float64) float64 {
+	valueStr := os.Getenv(key)
+	if len(valueStr) == 0 {
+		return fallback
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		envErrors = append(envErrors, fmt.Sprintf("%s=%q is not a number", key, valueStr))
+		return fallback
+	}
+	return value
+}
+
+func getenvDuration(key string, fallback time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+	if len(valueStr) == 0 {
+		return fallback
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		envErrors = append(envErrors, fmt.Sprintf("%s=%q is not a duration (e.g. 90s, 2h)", key, valueStr))
+		return fallback
+	}
+	return value
+}
+
+func getenvTime(key string, fallback time.Time) time.Time {
+	valueStr := os.Getenv(key)
+	if len(valueStr) == 0 {
+		return fallback
+	}
+	value, err := time.Parse(time.RFC3339, valueStr)
+	if err != nil {
+		envErrors = append(envErrors, fmt.Sprintf("%s=%q is not a RFC3339 time (e.g. 2024-01-01T00:00:00Z)", key, valueStr))
+		return fallback
+	}
+	return value
+}
+
+// getenvDelay parses a delay, a number of milliseconds or a distribution (see ParseDelay)
+func getenvDelay(key string, fallback DelayConfig) DelayConfig {
+	valueStr := os.Getenv(key)
+	if len(valueStr) == 0 {
+		return fallback
+	}
+	value, err := ParseDelay(valueStr)
+	if err != nil {
+		envErrors = append(envErrors, fmt.Sprintf("%s: %s", key, err))
+		return fallback
+	}
+	return value
+}
+
+// getenvMap parses a comma separated list of name=value pairs, e.g. "Authorization=Bearer xyz,X-Source=synth"
+func getenvMap(key string, fallback map[string]string) map[string]string {
+	valueStr := os.Getenv(key)
+	if len(valueStr) == 0 {
+		return fallback
+	}
+	value := map[string]string{}
+	for _, pair := range strings.Split(valueStr, ",") {
+		name, v, ok := strings.Cut(pair, "=")
+		if !ok || len(strings.TrimSpace(name)) == 0 {
+			envErrors = append(envErrors, fmt.Sprintf("%s: %q is not a name=value pair", key, pair))
+			return fallback
+		}
+		value[strings.TrimSpace(name)] = strings.TrimSpace(v)
 	}
 	return value
 }
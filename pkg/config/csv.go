@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// CsvColumns are the columns of the csv sink
+var CsvColumns = []string{"topic", "id", "ts", "date_ts", "status", "source", "source_name", "destination", "destination_name", "currency", "amount"}
+
+// CsvDelimiter parses the csv delimiter, a single character or "tab"
+func CsvDelimiter(delimiter string) (rune, error) {
+	if strings.EqualFold(delimiter, "tab") || delimiter == `\t` {
+		return '\t', nil
+	}
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) || comma == '"' || comma == '\r' || comma == '\n' || comma == utf8.RuneError {
+		return 0, fmt.Errorf("invalid csv delimiter %q, expected a single character", delimiter)
+	}
+	return comma, nil
+}
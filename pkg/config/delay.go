@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Delay distributions
+const (
+	DelayConstant    = "constant"    // Value
+	DelayUniform     = "uniform"     // Between Min and Max
+	DelayNormal      = "normal"      // Mean and Stddev
+	DelayExponential = "exponential" // Mean
+	DelayLogNormal   = "lognormal"   // Mean and Stddev of the delay (not of its logarithm)
+	DelayEmpirical   = "empirical"   // Interpolated Percentiles
+)
+
+// DelayConfig is the distribution of the delay of a status, in milliseconds.
+// A number is a constant delay, e.g. `completed: 3000`.
+// Min and Max bound the sampled delays of the normal, exponential and lognormal distributions, Max 0 means unbounded.
+type DelayConfig struct {
+	Distribution string             `mapstructure:"distribution"`
+	Value        float64            `mapstructure:"value"`
+	Min          float64            `mapstructure:"min"`
+	Max          float64            `mapstructure:"max"`
+	Mean         float64            `mapstructure:"mean"`
+	Stddev       float64            `mapstructure:"stddev"`
+	Percentiles  map[string]float64 `mapstructure:"percentiles"` // e.g. p50: 200, p99: 3000
+}
+
+// Constant returns a constant delay
+func Constant(ms float64) DelayConfig {
+	return DelayConfig{Distribution: DelayConstant, Value: ms}
+}
+
+// ParseDelay parses the short form of a delay, used by the DELAY_<STATUS> variables:
+// "3000" (constant), "uniform:100,500", "normal:1000,200", "exponential:500", "lognormal:1000,300" or "empirical:p50=200,p90=800,p99=3000"
+func ParseDelay(value string) (DelayConfig, error) {
+	value = strings.TrimSpace(value)
+	if ms, err := strconv.ParseFloat(value, 64); err == nil {
+		return Constant(ms), nil
+	}
+	name, args, ok := strings.Cut(value, ":")
+	if !ok {
+		return DelayConfig{}, fmt.Errorf("%q is not a number of milliseconds or a <distribution>:<parameters> delay", value)
+	}
+	d := DelayConfig{Distribution: strings.ToLower(strings.TrimSpace(name))}
+	if d.Distribution == DelayEmpirical {
+		d.Percentiles = map[string]float64{}
+		for _, point := range strings.Split(args, ",") {
+			p, v, ok := strings.Cut(point, "=")
+			ms, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if !ok || err != nil {
+				return DelayConfig{}, fmt.Errorf("%q: %q is not a percentile=milliseconds pair", value, point)
+			}
+			d.Percentiles[strings.TrimSpace(p)] = ms
+		}
+		return d, nil
+	}
+	var params []float64
+	for _, arg := range strings.Split(args, ",") {
+		param, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil {
+			return DelayConfig{}, fmt.Errorf("%q: %q is not a number", value, arg)
+		}
+		params = append(params, param)
+	}
+	expected := 2
+	switch d.Distribution {
+	case DelayConstant:
+		expected = 1
+		d.Value = params[0]
+	case DelayUniform:
+		if len(params) == 2 {
+			d.Min, d.Max = params[0], params[1]
+		}
+	case DelayNormal, DelayLogNormal:
+		if len(params) == 2 {
+			d.Mean, d.Stddev = params[0], params[1]
+		}
+	case DelayExponential:
+		expected = 1
+		d.Mean = params[0]
+	default:
+		return DelayConfig{}, fmt.Errorf("%q: unknown distribution %q", value, d.Distribution)
+	}
+	if len(params) != expected {
+		return DelayConfig{}, fmt.Errorf("%q: %s expects %d parameters, got %d", value, d.Distribution, expected, len(params))
+	}
+	return d, nil
+}
+
+// delayHook decodes the numbers as constant delays and the strings with ParseDelay
+func delayHook(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+	if t != reflect.TypeOf(DelayConfig{}) {
+		return data, nil
+	}
+	switch v := data.(type) {
+	case int:
+		return Constant(float64(v)), nil
+	case float64:
+		return Constant(v), nil
+	case string:
+		return ParseDelay(v)
+	case map[string]interface{}:
+		if _, ok := v["distribution"]; !ok {
+			return nil, fmt.Errorf("missing delay distribution in %v", v)
+		}
+	}
+	return data, nil
+}
+
+// Percentile point of an empirical distribution
+type Percentile struct {
+	Percentile float64 // 0 to 100
+	Value      float64
+}
+
+// Points returns the percentiles of the empirical distribution, sorted by percentile
+func (d DelayConfig) Points() ([]Percentile, error) {
+	var points []Percentile
+	for name, value := range d.Percentiles {
+		p, err := strconv.ParseFloat(strings.TrimPrefix(strings.ToLower(name), "p"), 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("percentile %q must be p0 to p100", name)
+		}
+		points = append(points, Percentile{Percentile: p, Value: value})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Percentile < points[j].Percentile })
+	return points, nil
+}
+
+// problems returns the invalid parameters of the distribution
+func (d DelayConfig) problems() []string {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	if d.Min < 0 || d.Max < 0 {
+		addf("min and max must not be negative, got %v and %v", d.Min, d.Max)
+	} else if d.Max > 0 && d.Max < d.Min {
+		addf("max must not be less than min, got %v < %v", d.Max, d.Min)
+	}
+	switch d.Distribution {
+	case DelayConstant:
+		if d.Value < 0 {
+			addf("value must not be negative, got %v", d.Value)
+		}
+	case DelayUniform:
+		if d.Max == 0 && d.Min > 0 { // Otherwise checked with the bounds
+			addf("max must not be less than min, got %v < %v", d.Max, d.Min)
+		}
+	case DelayNormal:
+		if d.Mean < 0 || d.Stddev < 0 {
+			addf("mean and stddev must not be negative, got %v and %v", d.Mean, d.Stddev)
+		}
+	case DelayExponential:
+		if d.Mean <= 0 {
+			addf("mean must be positive, got %v", d.Mean)
+		}
+	case DelayLogNormal:
+		if d.Mean <= 0 || d.Stddev < 0 {
+			addf("mean must be positive and stddev must not be negative, got %v and %v", d.Mean, d.Stddev)
+		}
+	case DelayEmpirical:
+		points, err := d.Points()
+		if err != nil {
+			addf("%s", err)
+		} else if len(points) == 0 {
+			addf("at least one percentile is required")
+		}
+		for i := range points {
+			if points[i].Value < 0 {
+				addf("p%v must not be negative, got %v", points[i].Percentile, points[i].Value)
+			} else if i > 0 && points[i].Value < points[i-1].Value {
+				addf("p%v must not be less than p%v, got %v < %v", points[i].Percentile, points[i-1].Percentile, points[i].Value, points[i-1].Value)
+			}
+		}
+	default:
+		addf("unknown distribution %q, expected one of %v", d.Distribution, []string{DelayConstant, DelayUniform, DelayNormal, DelayExponential, DelayLogNormal, DelayEmpirical})
+	}
+	for _, v := range []float64{d.Value, d.Min, d.Max, d.Mean, d.Stddev} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			addf("parameters must be finite numbers")
+			break
+		}
+	}
+	return problems
+}
+
+// TransitionKey is the delays key of the transition between two statuses, e.g. "initiated->failed"
+func TransitionKey(from string, to string) string {
+	return strings.ToLower(strings.TrimSpace(from)) + "->" + strings.ToLower(strings.TrimSpace(to))
+}
+
+// DelayKey normalizes a delays key, a status or a transition ("Initiated -> Failed")
+func DelayKey(key string) string {
+	if from, to, ok := strings.Cut(key, "->"); ok {
+		return TransitionKey(from, to)
+	}
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// WorkflowKey normalizes a workflow definition, or the statuses of a walked path, as the key of the workflow delays
+func WorkflowKey(statuses []string) string {
+	return strings.ToLower(strings.Join(statuses, ", "))
+}
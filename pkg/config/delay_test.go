@@ -0,0 +1,123 @@
+package config
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDelay(t *testing.T) {
+	tests := []struct {
+		value string
+		want  DelayConfig
+		err   string
+	}{
+		{value: "3000", want: Constant(3000)},
+		{value: " 250.5 ", want: Constant(250.5)},
+		{value: "constant:100", want: Constant(100)},
+		{value: "uniform:100,500", want: DelayConfig{Distribution: DelayUniform, Min: 100, Max: 500}},
+		{value: "Normal: 1000, 200", want: DelayConfig{Distribution: DelayNormal, Mean: 1000, Stddev: 200}},
+		{value: "lognormal:1000,300", want: DelayConfig{Distribution: DelayLogNormal, Mean: 1000, Stddev: 300}},
+		{value: "exponential:500", want: DelayConfig{Distribution: DelayExponential, Mean: 500}},
+		{value: "empirical:p50=200,p99=3000", want: DelayConfig{Distribution: DelayEmpirical, Percentiles: map[string]float64{"p50": 200, "p99": 3000}}},
+		{value: "constant:1,2", err: "constant expects 1 parameters, got 2"},
+		{value: "uniform:100", err: "uniform expects 2 parameters, got 1"},
+		{value: "normal:1,2,3", err: "normal expects 2 parameters, got 3"},
+		{value: "lognormal:1000", err: "lognormal expects 2 parameters, got 1"},
+		{value: "exponential:1,2", err: "exponential expects 1 parameters, got 2"},
+		{value: "uniform:100,abc", err: `"abc" is not a number`},
+		{value: "empirical:p50", err: `"p50" is not a percentile=milliseconds pair`},
+		{value: "poisson:3", err: `unknown distribution "poisson"`},
+		{value: "fast", err: "is not a number of milliseconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDelay(tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseDelay(%q) error = %v, want %q", tt.value, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDelay(%q) error = %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDelay(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelayProblems(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay DelayConfig
+		want  []string // substrings of the problems, in order
+	}{
+		{name: "constant", delay: Constant(100)},
+		{name: "negative constant", delay: Constant(-1), want: []string{"value must not be negative"}},
+		{name: "uniform", delay: DelayConfig{Distribution: DelayUniform, Min: 100, Max: 500}},
+		{name: "uniform without max", delay: DelayConfig{Distribution: DelayUniform, Min: 100}, want: []string{"max must not be less than min"}},
+		{name: "bounds", delay: DelayConfig{Distribution: DelayNormal, Mean: 100, Min: 500, Max: 100}, want: []string{"max must not be less than min"}},
+		{name: "negative bounds", delay: DelayConfig{Distribution: DelayExponential, Mean: 100, Min: -1}, want: []string{"min and max must not be negative"}},
+		{name: "negative stddev", delay: DelayConfig{Distribution: DelayNormal, Mean: 100, Stddev: -1}, want: []string{"mean and stddev must not be negative"}},
+		{name: "exponential mean", delay: DelayConfig{Distribution: DelayExponential}, want: []string{"mean must be positive"}},
+		{name: "lognormal mean", delay: DelayConfig{Distribution: DelayLogNormal, Stddev: 10}, want: []string{"mean must be positive"}},
+		{name: "empirical", delay: DelayConfig{Distribution: DelayEmpirical, Percentiles: map[string]float64{"p50": 200, "P90": 800, "p99.9": 3000}}},
+		{name: "empirical without percentiles", delay: DelayConfig{Distribution: DelayEmpirical}, want: []string{"at least one percentile is required"}},
+		{name: "empirical percentile name", delay: DelayConfig{Distribution: DelayEmpirical, Percentiles: map[string]float64{"p101": 200}}, want: []string{`percentile "p101" must be p0 to p100`}},
+		{name: "empirical negative", delay: DelayConfig{Distribution: DelayEmpirical, Percentiles: map[string]float64{"p50": -5}}, want: []string{"p50 must not be negative"}},
+		{name: "empirical ordering", delay: DelayConfig{Distribution: DelayEmpirical, Percentiles: map[string]float64{"p50": 800, "p90": 200, "p99": 3000}}, want: []string{"p90 must not be less than p50, got 200 < 800"}},
+		{name: "unknown", delay: DelayConfig{Distribution: "poisson"}, want: []string{`unknown distribution "poisson"`}},
+		{name: "not finite", delay: DelayConfig{Distribution: DelayNormal, Mean: math.Inf(1)}, want: []string{"parameters must be finite numbers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.delay.problems()
+			if len(got) != len(tt.want) {
+				t.Fatalf("problems() = %q, want %d problems %q", got, len(tt.want), tt.want)
+			}
+			for i := range tt.want {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("problems()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDelayKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "Completed", want: "completed"},
+		{key: " accounted->failed", want: "accounted->failed"},
+		{key: "Accounted -> Failed", want: "accounted->failed"},
+	}
+	for _, tt := range tests {
+		if got := DelayKey(tt.key); got != tt.want {
+			t.Errorf("DelayKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+// DELAY_<FROM>_<TO> sets the delay of a transition, next to the DELAY_<STATUS> delays
+func TestTransitionDelayEnv(t *testing.T) {
+	t.Setenv("DELAY_ACCOUNTED_FAILED", "8000")
+	t.Setenv("DELAY_VALIDATED_REJECTED", "uniform:100,200")
+	t.Setenv("DELAY_FAILED", "500")
+	c := Build("")
+	want := map[string]DelayConfig{
+		"accounted->failed":   Constant(8000),
+		"validated->rejected": {Distribution: DelayUniform, Min: 100, Max: 200},
+		"failed":              Constant(500),
+	}
+	for key, delay := range want {
+		if got := c.Datagen.Delays[key]; !reflect.DeepEqual(got, delay) {
+			t.Errorf("Delays[%q] = %+v, want %+v", key, got, delay)
+		}
+	}
+}
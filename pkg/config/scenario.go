@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadScenario reads a YAML or JSON scenario file into config.
+// Only the keys present in the file are replaced, maps (workflows, delays, topics, ...) are replaced as a whole.
+func LoadScenario(path string, config *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var scenario map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &scenario)
+	case ".json":
+		err = json.Unmarshal(content, &scenario)
+	default:
+		return fmt.Errorf("unsupported scenario file extension %q, expected .yaml, .yml or .json", filepath.Ext(path))
+	}
+	if err != nil {
+		return err
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           config,
+		DecodeHook:       mapstructure.ComposeDecodeHookFunc(mapstructure.StringToTimeDurationHookFunc(), mapstructure.StringToTimeHookFunc(time.RFC3339), delayHook),
+		ZeroFields:       true,
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(scenario); err != nil {
+		return err
+	}
+	if config.Datagen.Delays == nil {
+		config.Datagen.Delays = map[string]DelayConfig{}
+	}
+	return nil
+}
@@ -0,0 +1,453 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidationError lists all the problems found in the configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n - " + strings.Join(e.Problems, "\n - ")
+}
+
+// PaymentTopic returns the topic name for the payment status updates
+func PaymentTopic(status string) string {
+	return fmt.Sprintf("payment-%s", strings.ToLower(status))
+}
+
+// WorkflowStatuses splits a workflow definition ("Initiated, Validated, ...") into status names
+func WorkflowStatuses(workflow string) []string {
+	var statuses []string
+	for _, status := range strings.Split(workflow, ",") {
+		statuses = append(statuses, strings.TrimSpace(status))
+	}
+	return statuses
+}
+
+// Validate checks the whole configuration, statuses is the list of known status names.
+// All the problems are returned in a single *ValidationError.
+func (c Config) Validate(statuses []string) error {
+	problems := append([]string{}, c.envErrors...)
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	known := map[string]bool{}
+	for _, status := range statuses {
+		known[strings.ToLower(status)] = true
+	}
+
+	d := c.Datagen
+	switch d.Mode {
+	case ModeCount:
+		if d.Payments <= 0 {
+			addf("datagen.payments (NUM_PAYMENTS) must be positive, got %d", d.Payments)
+		}
+	case ModeDuration:
+		if d.Duration <= 0 {
+			addf("datagen.duration (DURATION) must be positive in %q mode, got %v", ModeDuration, d.Duration)
+		}
+	case ModeBackfill:
+		b := d.Backfill
+		if b.Start.IsZero() || b.End.IsZero() {
+			addf("datagen.backfill.start (BACKFILL_START) and datagen.backfill.end (BACKFILL_END) are required in %q mode", ModeBackfill)
+		} else if !b.End.After(b.Start) {
+			addf("datagen.backfill.end (BACKFILL_END) must be after datagen.backfill.start (BACKFILL_START), got %v and %v", b.End.Format(time.RFC3339), b.Start.Format(time.RFC3339))
+		}
+		if b.Rate <= 0 {
+			addf("datagen.backfill.rate (BACKFILL_RATE) must be positive in %q mode, got %v", ModeBackfill, b.Rate)
+		}
+		if d.PaymentsRate > 0 || d.EventsRate > 0 {
+			addf("datagen.paymentsRate (PAYMENTS_RATE) and datagen.eventsRate (EVENTS_RATE) throttle the real clock, they must be 0 in %q mode, got %v and %v", ModeBackfill, d.PaymentsRate, d.EventsRate)
+		}
+	case ModeContinuous:
+	default:
+		addf("datagen.mode (RUN_MODE) unknown mode %q, expected one of %v", d.Mode, []string{ModeCount, ModeContinuous, ModeDuration, ModeBackfill})
+	}
+	if d.Workers <= 0 {
+		addf("datagen.workers (NUM_WORKERS) must be positive, got %d", d.Workers)
+	}
+	if d.Sources <= 0 {
+		addf("datagen.sources (NUM_SOURCES) must be positive, got %d", d.Sources)
+	}
+	if d.Destinations <= 0 {
+		addf("datagen.destinations (NUM_DESTINATIONS) must be positive, got %d", d.Destinations)
+	}
+	if d.UpdateBanksInterval <= 0 {
+		addf("datagen.updateBanksInterval (UPDATE_BANKS_INTERVAL) must be positive, got %d", d.UpdateBanksInterval)
+	}
+
+	if d.Buffer < 0 {
+		addf("datagen.buffer (PAYMENTS_BUFFER) must not be negative, got %d", d.Buffer)
+	}
+	if d.PaymentsRate < 0 {
+		addf("datagen.paymentsRate (PAYMENTS_RATE) must not be negative, got %v", d.PaymentsRate)
+	}
+	if d.EventsRate < 0 {
+		addf("datagen.eventsRate (EVENTS_RATE) must not be negative, got %v", d.EventsRate)
+	}
+
+	switch c.Errors.Policy {
+	case ErrorRetry, ErrorSkip, ErrorAbort:
+	default:
+		addf("errors.policy (ERROR_POLICY) unknown policy %q, expected one of %v", c.Errors.Policy, []string{ErrorRetry, ErrorSkip, ErrorAbort})
+	}
+	if c.Errors.Retries < 0 {
+		addf("errors.retries (ERROR_RETRIES) must not be negative, got %d", c.Errors.Retries)
+	}
+	if c.Errors.Backoff < 0 {
+		addf("errors.backoff (ERROR_BACKOFF) must not be negative, got %v", c.Errors.Backoff)
+	}
+
+	switch c.Shutdown.Mode {
+	case ShutdownDrain, ShutdownAbandon:
+	default:
+		addf("shutdown.mode (SHUTDOWN_MODE) unknown mode %q, expected one of %v", c.Shutdown.Mode, []string{ShutdownDrain, ShutdownAbandon})
+	}
+	if c.Shutdown.Timeout <= 0 {
+		addf("shutdown.timeout (SHUTDOWN_TIMEOUT) must be positive, got %v", c.Shutdown.Timeout)
+	}
+
+	// Workflows, the statuses used by the model need a topic, and the steps (first status or transition) a delay
+	used := map[string]bool{}
+	steps := map[string]bool{}
+	switch d.Model {
+	case ModelWorkflows:
+		total := 0
+		for _, workflow := range sortedKeys(d.Workflows) {
+			weight := d.Workflows[workflow]
+			if weight < 0 {
+				addf("workflow %q: weight must not be negative, got %d", workflow, weight)
+			} else {
+				total += weight
+			}
+			previous := ""
+			for _, status := range WorkflowStatuses(workflow) {
+				if !known[strings.ToLower(status)] {
+					addf("workflow %q: unknown status %q, expected one of %v", workflow, status, statuses)
+					previous = ""
+					continue
+				}
+				used[strings.ToLower(status)] = true
+				if len(previous) == 0 {
+					steps[DelayKey(status)] = true
+				} else {
+					steps[TransitionKey(previous, status)] = true
+				}
+				previous = status
+			}
+		}
+		if len(d.Workflows) == 0 {
+			addf("datagen.workflows: at least one workflow is required")
+		} else if total <= 0 {
+			addf("datagen.workflows: total weight must be positive")
+		}
+	case ModelGraph:
+		used = validateGraph(d.Graph, known, statuses, steps, addf)
+	default:
+		addf("datagen.model (WORKFLOW_MODEL) unknown model %q, expected one of %v", d.Model, []string{ModelWorkflows, ModelGraph})
+	}
+
+	// Delays, by status or transition
+	delays := map[string]bool{}
+	for _, key := range sortedKeys(d.Delays) {
+		if !knownDelayKey(key, known) {
+			addf("datagen.delays: unknown status or transition %q", key)
+		}
+		for _, problem := range d.Delays[key].problems() {
+			addf("datagen.delays: delay for %q: %s", key, problem)
+		}
+		delays[DelayKey(key)] = true
+	}
+	// The transitions without a delay fall back to the status delay
+	missing := map[string]bool{}
+	for _, step := range sortedKeys(steps) {
+		status := step
+		if _, to, ok := strings.Cut(step, "->"); ok {
+			status = to
+		}
+		if !delays[step] && !delays[status] {
+			missing[status] = true
+		}
+	}
+	for _, status := range sortedKeys(missing) {
+		addf("datagen.delays: missing delay for status %q (DELAY_%s)", status, strings.ToUpper(status))
+	}
+	workflows := map[string]bool{}
+	for _, workflow := range sortedKeys(d.Workflows) {
+		workflows[WorkflowKey(WorkflowStatuses(workflow))] = true
+	}
+	for _, workflow := range sortedKeys(d.WorkflowDelays) {
+		if d.Model == ModelWorkflows && !workflows[WorkflowKey(WorkflowStatuses(workflow))] {
+			addf("datagen.workflowDelays: unknown workflow %q", workflow)
+		}
+		for _, key := range sortedKeys(d.WorkflowDelays[workflow]) {
+			if !knownDelayKey(key, known) {
+				addf("datagen.workflowDelays: workflow %q: unknown status or transition %q", workflow, key)
+			}
+			for _, problem := range d.WorkflowDelays[workflow][key].problems() {
+				addf("datagen.workflowDelays: workflow %q: delay for %q: %s", workflow, key, problem)
+			}
+		}
+	}
+
+	// Topics
+	if strings.EqualFold(c.Sink.Type, "kafka") {
+		switch c.Kafka.Serialization {
+		case SerializationAvro, SerializationJsonSchema, SerializationProtobuf, SerializationJson:
+		default:
+			addf("kafka.serialization (KAFKA_SERIALIZATION) unknown serialization %q, expected one of %v", c.Kafka.Serialization, []string{SerializationAvro, SerializationJsonSchema, SerializationProtobuf, SerializationJson})
+		}
+		sr := c.SchemaRegistry
+		switch sr.SubjectNameStrategy {
+		case SubjectTopicName, SubjectRecordName, SubjectTopicRecordName:
+		default:
+			addf("schemaRegistry.subjectNameStrategy (SCHEMA_REGISTRY_SUBJECT_NAME_STRATEGY) unknown strategy %q, expected one of %v", sr.SubjectNameStrategy, []string{SubjectTopicName, SubjectRecordName, SubjectTopicRecordName})
+		}
+		if sr.AutoRegister && sr.UseLatestVersion {
+			addf("schemaRegistry.useLatestVersion (SCHEMA_REGISTRY_USE_LATEST_VERSION) requires schemaRegistry.autoRegister (SCHEMA_REGISTRY_AUTO_REGISTER) false")
+		}
+		for _, topic := range sortedKeys(c.Kafka.Topics) {
+			if c.Kafka.Topics[topic] <= 0 {
+				addf("kafka.topics: topic %q must have a positive number of partitions, got %d", topic, c.Kafka.Topics[topic])
+			}
+		}
+		if _, ok := c.Kafka.Topics["banks"]; !ok {
+			addf("kafka.topics: missing topic %q", "banks")
+		}
+		for _, status := range sortedKeys(used) {
+			if _, ok := c.Kafka.Topics[PaymentTopic(status)]; !ok {
+				addf("kafka.topics: missing topic %q for status %q", PaymentTopic(status), status)
+			}
+		}
+	}
+
+	// File sinks
+	sinkType := strings.ToLower(c.Sink.Type)
+	switch sinkType {
+	case "jsonl", "avro", "parquet", "csv":
+		if len(c.Sink.File.Dir) == 0 {
+			addf("sink.file.dir (SINK_FILE_DIR) must not be empty")
+		}
+		if c.Sink.File.MaxBytes < 0 {
+			addf("sink.file.maxBytes (SINK_FILE_MAX_BYTES) must not be negative, got %d", c.Sink.File.MaxBytes)
+		}
+	}
+
+	if (sinkType == "avro" || sinkType == "parquet") && c.Sink.File.Gzip {
+		addf("sink.file.gzip (SINK_FILE_GZIP) is not supported by the %s sink, use the %s compression instead", sinkType, sinkType)
+	}
+	if sinkType == "avro" {
+		switch strings.ToLower(c.Sink.Avro.Codec) {
+		case "null", "deflate", "snappy":
+		default:
+			addf("sink.avro.codec (SINK_AVRO_CODEC) unknown codec %q, expected one of %v", c.Sink.Avro.Codec, []string{"null", "deflate", "snappy"})
+		}
+		if c.Sink.Avro.BlockRecords <= 0 {
+			addf("sink.avro.blockRecords (SINK_AVRO_BLOCK_RECORDS) must be positive, got %d", c.Sink.Avro.BlockRecords)
+		}
+	}
+	if sinkType == "parquet" {
+		switch strings.ToLower(c.Sink.Parquet.Compression) {
+		case "none", "uncompressed", "snappy", "gzip", "zstd":
+		default:
+			addf("sink.parquet.compression (SINK_PARQUET_COMPRESSION) unknown compression %q, expected one of %v", c.Sink.Parquet.Compression, []string{"none", "snappy", "gzip", "zstd"})
+		}
+		if c.Sink.Parquet.RowGroupRows <= 0 {
+			addf("sink.parquet.rowGroupRows (SINK_PARQUET_ROW_GROUP_ROWS) must be positive, got %d", c.Sink.Parquet.RowGroupRows)
+		}
+	}
+
+	if sinkType == "csv" {
+		if _, err := CsvDelimiter(c.Sink.Csv.Delimiter); err != nil {
+			addf("sink.csv.delimiter (SINK_CSV_DELIMITER) %s", err)
+		}
+		if len(c.Sink.Csv.Columns) == 0 {
+			addf("sink.csv.columns (SINK_CSV_COLUMNS) must not be empty, expected some of %v", CsvColumns)
+		}
+		columns := map[string]bool{}
+		for _, column := range CsvColumns {
+			columns[column] = true
+		}
+		for _, column := range c.Sink.Csv.Columns {
+			if !columns[strings.ToLower(strings.TrimSpace(column))] {
+				addf("sink.csv.columns (SINK_CSV_COLUMNS) unknown column %q, expected one of %v", column, CsvColumns)
+			}
+		}
+	}
+
+	if sinkType == "console" {
+		switch strings.ToLower(c.Sink.Console.Format) {
+		case "line", "json":
+		default:
+			addf("sink.console.format (SINK_CONSOLE_FORMAT) unknown format %q, expected one of %v", c.Sink.Console.Format, []string{"line", "json"})
+		}
+	}
+
+	// Schema evolution
+	sc := c.Schema
+	if sc.Version != 1 && sc.Version != 2 {
+		addf("schema.version (SCHEMA_VERSION) must be 1 or 2, got %d", sc.Version)
+	}
+	if sc.SwitchTo != 0 && sc.SwitchTo != 1 && sc.SwitchTo != 2 {
+		addf("schema.switchTo (SCHEMA_SWITCH_TO) must be 0 (no switch), 1 or 2, got %d", sc.SwitchTo)
+	}
+	if sc.SwitchAfterPayments < 0 {
+		addf("schema.switchAfterPayments (SCHEMA_SWITCH_AFTER_PAYMENTS) must not be negative, got %d", sc.SwitchAfterPayments)
+	}
+	if sc.SwitchAfter < 0 {
+		addf("schema.switchAfter (SCHEMA_SWITCH_AFTER) must not be negative, got %v", sc.SwitchAfter)
+	}
+	if sc.SwitchTo != 0 && sc.SwitchAfterPayments == 0 && sc.SwitchAfter == 0 {
+		addf("schema.switchTo (SCHEMA_SWITCH_TO) requires schema.switchAfterPayments (SCHEMA_SWITCH_AFTER_PAYMENTS) or schema.switchAfter (SCHEMA_SWITCH_AFTER)")
+	}
+	if (sc.Version == 2 || sc.SwitchTo == 2) && (!strings.EqualFold(c.Sink.Type, "kafka") || c.Kafka.Serialization != SerializationAvro) {
+		addf("schema version 2 requires the kafka sink with avro serialization, got %q sink with %q serialization", c.Sink.Type, c.Kafka.Serialization)
+	}
+
+	if strings.EqualFold(c.Sink.Type, "webhook") {
+		w := c.Sink.Webhook
+		if len(w.Url) == 0 {
+			addf("sink.webhook.url (SINK_WEBHOOK_URL) must not be empty")
+		} else if !strings.HasPrefix(w.Url, "http://") && !strings.HasPrefix(w.Url, "https://") {
+			addf("sink.webhook.url (SINK_WEBHOOK_URL) must be an http(s) URL, got %q", w.Url)
+		}
+		if w.Concurrency <= 0 {
+			addf("sink.webhook.concurrency (SINK_WEBHOOK_CONCURRENCY) must be positive, got %d", w.Concurrency)
+		}
+		if w.Timeout <= 0 {
+			addf("sink.webhook.timeout (SINK_WEBHOOK_TIMEOUT) must be positive, got %v", w.Timeout)
+		}
+		if w.Retries < 0 {
+			addf("sink.webhook.retries (SINK_WEBHOOK_RETRIES) must not be negative, got %d", w.Retries)
+		}
+		if w.Backoff < 0 {
+			addf("sink.webhook.backoff (SINK_WEBHOOK_BACKOFF) must not be negative, got %v", w.Backoff)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateGraph checks the transition graph, returns the statuses reachable from the start status.
+// The start status and the transitions from the reachable statuses are added to steps.
+func validateGraph(g GraphConfig, known map[string]bool, statuses []string, steps map[string]bool, addf func(format string, args ...interface{})) map[string]bool {
+	if g.MaxSteps <= 0 {
+		addf("datagen.graph.maxSteps must be positive, got %d", g.MaxSteps)
+	}
+	start := strings.ToLower(g.Start)
+	if !known[start] {
+		addf("datagen.graph.start: unknown status %q, expected one of %v", g.Start, statuses)
+		return map[string]bool{}
+	}
+	terminal := map[string]bool{}
+	for _, status := range g.Terminal {
+		if !known[strings.ToLower(status)] {
+			addf("datagen.graph.terminal: unknown status %q, expected one of %v", status, statuses)
+			continue
+		}
+		terminal[strings.ToLower(status)] = true
+	}
+	if len(terminal) == 0 {
+		addf("datagen.graph.terminal: at least one terminal status is required")
+	}
+	// Transitions by lower case status
+	transitions := map[string]map[string]float64{}
+	for _, from := range sortedKeys(g.Transitions) {
+		if !known[strings.ToLower(from)] {
+			addf("datagen.graph.transitions: unknown status %q, expected one of %v", from, statuses)
+			continue
+		}
+		if terminal[strings.ToLower(from)] {
+			addf("datagen.graph.transitions: terminal status %q must not have transitions", from)
+			continue
+		}
+		next := map[string]float64{}
+		total := 0.0
+		for _, to := range sortedKeys(g.Transitions[from]) {
+			p := g.Transitions[from][to]
+			if !known[strings.ToLower(to)] {
+				addf("datagen.graph.transitions: %q -> unknown status %q, expected one of %v", from, to, statuses)
+				continue
+			}
+			if p <= 0 || p > 1 {
+				addf("datagen.graph.transitions: %q -> %q probability must be in (0, 1], got %v", from, to, p)
+				continue
+			}
+			next[strings.ToLower(to)] += p
+			total += p
+		}
+		if math.Abs(total-1) > 1e-3 {
+			addf("datagen.graph.transitions: probabilities from %q must sum to 1, got %v", from, total)
+		}
+		transitions[strings.ToLower(from)] = next
+	}
+	// Reachable statuses from the start
+	reachable := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for to := range transitions[from] {
+			if !reachable[to] {
+				reachable[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	// Statuses reaching a terminal status, walking the transitions backwards
+	reaching := map[string]bool{}
+	for status := range terminal {
+		reaching[status] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for from, next := range transitions {
+			for to := range next {
+				if reaching[to] && !reaching[from] {
+					reaching[from] = true
+					changed = true
+				}
+			}
+		}
+	}
+	steps[start] = true
+	for _, status := range sortedKeys(reachable) {
+		for to := range transitions[status] {
+			steps[TransitionKey(status, to)] = true
+		}
+		if !terminal[status] && len(transitions[status]) == 0 {
+			addf("datagen.graph.transitions: status %q is not terminal and has no transitions", status)
+		} else if !reaching[status] {
+			addf("datagen.graph.transitions: no terminal status can be reached from %q", status)
+		}
+	}
+	return reachable
+}
+
+// knownDelayKey checks the statuses of a delays key, a status or a transition (from->to)
+func knownDelayKey(key string, known map[string]bool) bool {
+	if from, to, ok := strings.Cut(DelayKey(key), "->"); ok {
+		return known[from] && known[to]
+	}
+	return known[DelayKey(key)]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var statuses = []string{"Initiated", "Completed", "Failed", "Canceled", "Validated", "Accounted", "Rejected", "Pending"}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string // substrings of the problems, empty if valid
+	}{
+		{name: "defaults", change: func(c *Config) {}},
+		{name: "counts", change: func(c *Config) {
+			c.Datagen.Payments = 0
+			c.Datagen.Workers = -1
+			c.Datagen.Sources = 0
+		}, want: []string{
+			"datagen.payments (NUM_PAYMENTS) must be positive, got 0",
+			"datagen.workers (NUM_WORKERS) must be positive, got -1",
+			"datagen.sources (NUM_SOURCES) must be positive, got 0",
+		}},
+		{name: "unknown status", change: func(c *Config) {
+			c.Datagen.Workflows = map[string]int{"Initiated, Settled": 1}
+		}, want: []string{`workflow "Initiated, Settled": unknown status "Settled"`}},
+		{name: "workflow weights", change: func(c *Config) {
+			c.Datagen.Workflows = map[string]int{"Initiated, Completed": 0, "Initiated, Failed": -1}
+		}, want: []string{
+			`workflow "Initiated, Failed": weight must not be negative, got -1`,
+			"datagen.workflows: total weight must be positive",
+		}},
+		{name: "no workflows", change: func(c *Config) {
+			c.Datagen.Workflows = map[string]int{}
+		}, want: []string{"datagen.workflows: at least one workflow is required"}},
+		{name: "missing delay", change: func(c *Config) {
+			delete(c.Datagen.Delays, "completed")
+		}, want: []string{`missing delay for status "completed" (DELAY_COMPLETED)`}},
+		{name: "missing topic", change: func(c *Config) {
+			delete(c.Kafka.Topics, "payment-completed")
+		}, want: []string{`kafka.topics: missing topic "payment-completed" for status "completed"`}},
+		{name: "topic partitions", change: func(c *Config) {
+			c.Kafka.Topics["banks"] = 0
+		}, want: []string{`kafka.topics: topic "banks" must have a positive number of partitions, got 0`}},
+		{name: "unknown delay status", change: func(c *Config) {
+			c.Datagen.Delays["settled"] = Constant(100)
+		}, want: []string{`datagen.delays: unknown status or transition "settled"`}},
+		{name: "transition delays", change: func(c *Config) {
+			delete(c.Datagen.Delays, "completed")
+			c.Datagen.Delays["accounted->completed"] = Constant(500)
+		}},
+		{name: "missing transition delay", change: func(c *Config) {
+			delete(c.Datagen.Delays, "failed")
+			c.Datagen.Delays["accounted->failed"] = Constant(500)
+		}, want: []string{`missing delay for status "failed" (DELAY_FAILED)`}},
+		{name: "unknown transition status", change: func(c *Config) {
+			c.Datagen.Delays["Accounted -> Settled"] = Constant(500)
+		}, want: []string{`datagen.delays: unknown status or transition "Accounted -> Settled"`}},
+		{name: "invalid delay", change: func(c *Config) {
+			c.Datagen.Delays["completed"] = DelayConfig{Distribution: DelayExponential}
+		}, want: []string{`datagen.delays: delay for "completed": mean must be positive`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaults()
+			tt.change(&c)
+			checkProblems(t, c.Validate(statuses), tt.want)
+		})
+	}
+}
+
+func TestValidateGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(g *GraphConfig)
+		want   []string // substrings of the problems, empty if valid
+	}{
+		{name: "defaults", change: func(g *GraphConfig) {}},
+		{name: "unknown start", change: func(g *GraphConfig) {
+			g.Start = "Created"
+		}, want: []string{`datagen.graph.start: unknown status "Created"`}},
+		{name: "unknown statuses", change: func(g *GraphConfig) {
+			g.Terminal = append(g.Terminal, "Settled")
+			g.Transitions["Accounted"] = map[string]float64{"Completed": 0.9, "Settled": 0.1}
+		}, want: []string{
+			`datagen.graph.terminal: unknown status "Settled"`,
+			`datagen.graph.transitions: "Accounted" -> unknown status "Settled"`,
+		}},
+		{name: "sum to 1", change: func(g *GraphConfig) {
+			g.Transitions["Initiated"] = map[string]float64{"Validated": 0.5, "Rejected": 0.2}
+		}, want: []string{`probabilities from "Initiated" must sum to 1, got 0.7`}},
+		{name: "probability", change: func(g *GraphConfig) {
+			g.Transitions["Pending"] = map[string]float64{"Validated": 1.5}
+		}, want: []string{`"Pending" -> "Validated" probability must be in (0, 1], got 1.5`}},
+		{name: "terminal transitions", change: func(g *GraphConfig) {
+			g.Transitions["Completed"] = map[string]float64{"Canceled": 1}
+		}, want: []string{`terminal status "Completed" must not have transitions`}},
+		{name: "no terminal", change: func(g *GraphConfig) {
+			g.Terminal = nil
+		}, want: []string{"at least one terminal status is required"}},
+		{name: "dead end", change: func(g *GraphConfig) {
+			delete(g.Transitions, "Pending")
+		}, want: []string{`status "pending" is not terminal and has no transitions`}},
+		{name: "loop without exit", change: func(g *GraphConfig) {
+			g.Transitions["Validated"] = map[string]float64{"Pending": 1}
+		}, want: []string{
+			`no terminal status can be reached from "validated"`,
+			`no terminal status can be reached from "pending"`,
+		}},
+		{name: "max steps", change: func(g *GraphConfig) {
+			g.MaxSteps = 0
+		}, want: []string{"datagen.graph.maxSteps must be positive, got 0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := defaults()
+			c.Datagen.Model = ModelGraph
+			tt.change(&c.Datagen.Graph)
+			checkProblems(t, c.Validate(statuses), tt.want)
+		})
+	}
+}
+
+// All the invalid environment variables are reported together with the other problems
+func TestValidateEnvErrors(t *testing.T) {
+	t.Setenv("NUM_PAYMENTS", "many")
+	t.Setenv("SEQUENTIAL_WORKFLOW", "maybe")
+	t.Setenv("UPDATE_BANKS_INTERVAL", "soon")
+	t.Setenv("NUM_WORKERS", "0")
+	c := Build("")
+	checkProblems(t, c.Validate(statuses), []string{
+		`NUM_PAYMENTS="many" is not an integer`,
+		`SEQUENTIAL_WORKFLOW="maybe" is not a boolean`,
+		`UPDATE_BANKS_INTERVAL="soon" is not an integer`,
+		"datagen.workers (NUM_WORKERS) must be positive, got 0",
+	})
+}
+
+// checkProblems checks that err lists the wanted problems (substrings), or is nil when none is wanted
+func checkProblems(t *testing.T, err error, want []string) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Fatalf("Validate() = %v, want nil", err)
+		}
+		return
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %v, want a *ValidationError", err)
+	}
+	for _, w := range want {
+		found := false
+		for _, problem := range verr.Problems {
+			found = found || strings.Contains(problem, w)
+		}
+		if !found {
+			t.Errorf("Validate() problems %q, missing %q", verr.Problems, w)
+		}
+	}
+}
@@ -13,9 +13,24 @@ import (
 type Datagen struct {
 	Sources      []model.Bank
 	Destinations []model.Bank
+	rng          *rand.Rand
 }
 
-func NewDatagen(sourcesNum int, destinationsNum int) Datagen {
+// Seed returns the given seed, or a time based seed when it is 0
+func Seed(seed int64) int64 {
+	if seed == 0 {
+		return time.Now().UnixNano()
+	}
+	return seed
+}
+
+// NewDatagen builds the bank catalogue, the same seed generates the same banks and payments except their timestamps (real clock)
+func NewDatagen(sourcesNum int, destinationsNum int, seed int64) Datagen {
+	rng := rand.New(rand.NewSource(seed))
+	// faker random sources: used for names, currencies and ids
+	faker.SetRandomSource(faker.NewSafeSource(rand.NewSource(rng.Int63())))
+	faker.SetCryptoSource(rand.New(rand.NewSource(rng.Int63())))
+
 	var sources []model.Bank
 	var destinations []model.Bank
 	for i := 0; i < sourcesNum; i++ {
@@ -27,27 +42,39 @@ func NewDatagen(sourcesNum int, destinationsNum int) Datagen {
 	return Datagen{
 		Sources:      sources,
 		Destinations: destinations,
+		rng:          rng,
 	}
 }
 
+// GeneratePayment is not safe for concurrent use, call it from a single goroutine
 func (d *Datagen) GeneratePayment() model.Payment {
 	// Build Payment
 	max := 99999.0
 	min := 0.1
 	// Get random source
-	source := d.Sources[rand.Intn(len(d.Sources))]
-	destination := d.Destinations[rand.Intn(len(d.Destinations))]
+	source := d.Sources[d.rng.Intn(len(d.Sources))]
+	destination := d.Destinations[d.rng.Intn(len(d.Destinations))]
 	return model.Payment{
 		Id:          faker.UUIDDigit(),
 		Ts:          time.Now().UnixNano() / 1e6,
 		Destination: destination.Id,
 		Source:      source.Id,
 		Currency:    faker.Currency(),
-		Amount:      min + rand.Float64()*(max-min),
+		Amount:      min + d.rng.Float64()*(max-min),
 		Status:      Initiated.String(),
 	}
 }
 
+// SetCreated sets the creation and update time of the banks, e.g. the start of the backfill window
+func (d *Datagen) SetCreated(ts time.Time) {
+	for _, banks := range [][]model.Bank{d.Sources, d.Destinations} {
+		for i := range banks {
+			banks[i].Created_ts = ts.Format(time.RFC3339)
+			banks[i].Updated_ts = ts.Format(time.RFC3339)
+		}
+	}
+}
+
 func (d *Datagen) GetBanks() []model.Bank {
 	return append(d.Sources, d.Destinations...)
 }
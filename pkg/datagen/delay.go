@@ -0,0 +1,133 @@
+package datagen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"time"
+)
+
+// sampler returns a delay in milliseconds
+type sampler func(rng *rand.Rand) float64
+
+// Delays samples the delays of the workflow statuses from the configured distributions.
+// The delay of a transition (from->to) falls back to the delay of the status.
+type Delays struct {
+	delays    map[string]sampler            // by status or transition
+	workflows map[string]map[string]sampler // by workflow key, then status or transition, overrides the delays
+	rng       *rand.Rand
+}
+
+func NewDelays(cfg config.Config, seed int64) (Delays, error) {
+	d := Delays{
+		delays:    map[string]sampler{},
+		workflows: map[string]map[string]sampler{},
+		rng:       rand.New(rand.NewSource(seed)),
+	}
+	for key, delay := range cfg.Datagen.Delays {
+		s, err := newSampler(delay)
+		if err != nil {
+			return Delays{}, fmt.Errorf("delay for %q: %w", key, err)
+		}
+		d.delays[config.DelayKey(key)] = s
+	}
+	for workflow, delays := range cfg.Datagen.WorkflowDelays {
+		key := config.WorkflowKey(config.WorkflowStatuses(workflow))
+		d.workflows[key] = map[string]sampler{}
+		for status, delay := range delays {
+			s, err := newSampler(delay)
+			if err != nil {
+				return Delays{}, fmt.Errorf("workflow %q: delay for %q: %w", workflow, status, err)
+			}
+			d.workflows[key][config.DelayKey(status)] = s
+		}
+	}
+	return d, nil
+}
+
+// Sample returns the delay of each status of the workflow.
+// Not safe for concurrent use, the same seed samples the same delays.
+func (d Delays) Sample(workflow []Status) []time.Duration {
+	var names []string
+	for _, status := range workflow {
+		names = append(names, status.String())
+	}
+	overrides := d.workflows[config.WorkflowKey(names)]
+	delays := make([]time.Duration, len(workflow))
+	for i, status := range workflow {
+		keys := []string{config.DelayKey(status.String())}
+		if i > 0 {
+			keys = []string{config.TransitionKey(workflow[i-1].String(), status.String()), keys[0]}
+		}
+		if s := lookup(keys, overrides, d.delays); s != nil {
+			delays[i] = time.Duration(s(d.rng) * float64(time.Millisecond))
+		}
+	}
+	return delays
+}
+
+// lookup returns the first sampler found, by map then by key
+func lookup(keys []string, samplers ...map[string]sampler) sampler {
+	for _, m := range samplers {
+		for _, key := range keys {
+			if s, ok := m[key]; ok {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+func newSampler(cfg config.DelayConfig) (sampler, error) {
+	var s sampler
+	switch cfg.Distribution {
+	case config.DelayConstant:
+		return func(*rand.Rand) float64 { return cfg.Value }, nil
+	case config.DelayUniform:
+		return func(rng *rand.Rand) float64 { return cfg.Min + rng.Float64()*(cfg.Max-cfg.Min) }, nil
+	case config.DelayNormal:
+		s = func(rng *rand.Rand) float64 { return cfg.Mean + rng.NormFloat64()*cfg.Stddev }
+	case config.DelayExponential:
+		s = func(rng *rand.Rand) float64 { return rng.ExpFloat64() * cfg.Mean }
+	case config.DelayLogNormal:
+		// Parameters of the underlying normal distribution, from the mean and stddev of the delay
+		sigma := math.Sqrt(math.Log(1 + (cfg.Stddev*cfg.Stddev)/(cfg.Mean*cfg.Mean)))
+		mu := math.Log(cfg.Mean) - sigma*sigma/2
+		s = func(rng *rand.Rand) float64 { return math.Exp(mu + rng.NormFloat64()*sigma) }
+	case config.DelayEmpirical:
+		points, err := cfg.Points()
+		if err != nil {
+			return nil, err
+		}
+		if len(points) == 0 {
+			return nil, fmt.Errorf("no percentiles")
+		}
+		s = func(rng *rand.Rand) float64 { return interpolate(points, rng.Float64()*100) }
+	default:
+		return nil, fmt.Errorf("unknown distribution %q", cfg.Distribution)
+	}
+	// Bounded, never negative
+	return func(rng *rand.Rand) float64 {
+		v := math.Max(s(rng), cfg.Min)
+		if cfg.Max > 0 {
+			v = math.Min(v, cfg.Max)
+		}
+		return math.Max(v, 0)
+	}, nil
+}
+
+// interpolate returns the value of the percentile p (inverse CDF), linear between the points.
+// The first and last values are used below and above the given percentiles.
+func interpolate(points []config.Percentile, p float64) float64 {
+	if p <= points[0].Percentile {
+		return points[0].Value
+	}
+	for i := 1; i < len(points); i++ {
+		if p <= points[i].Percentile {
+			lo, hi := points[i-1], points[i]
+			return lo.Value + (hi.Value-lo.Value)*(p-lo.Percentile)/(hi.Percentile-lo.Percentile)
+		}
+	}
+	return points[len(points)-1].Value
+}
@@ -0,0 +1,142 @@
+package datagen
+
+import (
+	"math"
+	"math/rand"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInterpolate(t *testing.T) {
+	points := []config.Percentile{{Percentile: 50, Value: 200}, {Percentile: 90, Value: 1000}, {Percentile: 99, Value: 3000}}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{p: 0, want: 200}, // Below the lowest percentile
+		{p: 50, want: 200},
+		{p: 70, want: 600},
+		{p: 90, want: 1000},
+		{p: 94.5, want: 2000},
+		{p: 99, want: 3000},
+		{p: 100, want: 3000}, // Above the highest percentile
+	}
+	for _, tt := range tests {
+		if got := interpolate(points, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("interpolate(p%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestSamplerBounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay config.DelayConfig
+		min   float64
+		max   float64
+	}{
+		{name: "constant", delay: config.Constant(100), min: 100, max: 100},
+		{name: "uniform", delay: config.DelayConfig{Distribution: config.DelayUniform, Min: 100, Max: 200}, min: 100, max: 200},
+		{name: "normal never negative", delay: config.DelayConfig{Distribution: config.DelayNormal, Mean: 10, Stddev: 100}, min: 0, max: math.Inf(1)},
+		{name: "normal clamped", delay: config.DelayConfig{Distribution: config.DelayNormal, Mean: 1000, Stddev: 500, Min: 800, Max: 1200}, min: 800, max: 1200},
+		{name: "exponential clamped", delay: config.DelayConfig{Distribution: config.DelayExponential, Mean: 500, Min: 100, Max: 600}, min: 100, max: 600},
+		{name: "lognormal clamped", delay: config.DelayConfig{Distribution: config.DelayLogNormal, Mean: 1000, Stddev: 3000, Max: 2000}, min: 0, max: 2000},
+		{name: "empirical", delay: config.DelayConfig{Distribution: config.DelayEmpirical, Percentiles: map[string]float64{"p10": 100, "p90": 900}}, min: 100, max: 900},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newSampler(tt.delay)
+			if err != nil {
+				t.Fatalf("newSampler(%+v) error = %v", tt.delay, err)
+			}
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < 10000; i++ {
+				if v := s(rng); v < tt.min || v > tt.max {
+					t.Fatalf("sampled %v, want between %v and %v", v, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestSamplerErrors(t *testing.T) {
+	tests := []config.DelayConfig{
+		{Distribution: "poisson"},
+		{Distribution: config.DelayEmpirical},
+		{Distribution: config.DelayEmpirical, Percentiles: map[string]float64{"median": 200}},
+	}
+	for _, delay := range tests {
+		if _, err := newSampler(delay); err == nil {
+			t.Errorf("newSampler(%+v) error = nil, want an error", delay)
+		}
+	}
+}
+
+// The lognormal mean and stddev are the ones of the delay, not of its logarithm
+func TestLogNormalMoments(t *testing.T) {
+	tests := []struct {
+		mean   float64
+		stddev float64
+	}{
+		{mean: 1000, stddev: 300},
+		{mean: 200, stddev: 400},
+	}
+	for _, tt := range tests {
+		s, err := newSampler(config.DelayConfig{Distribution: config.DelayLogNormal, Mean: tt.mean, Stddev: tt.stddev})
+		if err != nil {
+			t.Fatalf("newSampler error = %v", err)
+		}
+		rng := rand.New(rand.NewSource(1))
+		const n = 200000
+		var sum, sumSq float64
+		for i := 0; i < n; i++ {
+			v := s(rng)
+			sum += v
+			sumSq += v * v
+		}
+		mean := sum / n
+		stddev := math.Sqrt(sumSq/n - mean*mean)
+		if math.Abs(mean-tt.mean) > 0.02*tt.mean {
+			t.Errorf("lognormal(%v, %v): sampled mean %v", tt.mean, tt.stddev, mean)
+		}
+		if math.Abs(stddev-tt.stddev) > 0.1*tt.stddev {
+			t.Errorf("lognormal(%v, %v): sampled stddev %v", tt.mean, tt.stddev, stddev)
+		}
+	}
+}
+
+// The delay of a transition (from->to) falls back to the delay of the status, the workflow delays override both
+func TestSampleTransitionFallback(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Datagen.Delays = map[string]config.DelayConfig{
+		"initiated":            config.Constant(100),
+		"validated":            config.Constant(1000),
+		"failed":               config.Constant(2000),
+		"Validated -> Failed":  config.Constant(5000),
+		"accounted->completed": config.Constant(300),
+	}
+	cfg.Datagen.WorkflowDelays = map[string]map[string]config.DelayConfig{
+		"Initiated, Failed": {"failed": config.Constant(50)},
+	}
+	d, err := NewDelays(cfg, 1)
+	if err != nil {
+		t.Fatalf("NewDelays error = %v", err)
+	}
+	tests := []struct {
+		workflow []Status
+		want     []time.Duration
+	}{
+		{workflow: []Status{Initiated, Validated, Failed}, want: []time.Duration{100 * time.Millisecond, time.Second, 5 * time.Second}},
+		{workflow: []Status{Initiated, Validated, Accounted, Failed}, want: []time.Duration{100 * time.Millisecond, time.Second, 0, 2 * time.Second}},
+		{workflow: []Status{Initiated, Validated, Accounted, Completed}, want: []time.Duration{100 * time.Millisecond, time.Second, 0, 300 * time.Millisecond}},
+		{workflow: []Status{Initiated, Failed}, want: []time.Duration{100 * time.Millisecond, 50 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		got := d.Sample(tt.workflow)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Sample(%v) = %v, want %v", tt.workflow, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package datagen
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -14,6 +15,7 @@ const (
 	Validated Status = "Validated"
 	Accounted Status = "Accounted"
 	Rejected  Status = "Rejected"
+	Pending   Status = "Pending"
 )
 
 var statusMap = map[string]Status{
@@ -24,6 +26,7 @@ var statusMap = map[string]Status{
 	"validated": Validated,
 	"accounted": Accounted,
 	"rejected":  Rejected,
+	"pending":   Pending,
 }
 
 func (s Status) String() string {
@@ -35,6 +38,23 @@ func GetStatus(str string) Status {
 	return c
 }
 
+// ParseStatus returns the Status for the given name (case insensitive) or an error if unknown
+func ParseStatus(str string) (Status, error) {
+	c, ok := statusMap[strings.ToLower(str)]
+	if !ok {
+		return c, fmt.Errorf("unknown status %q", str)
+	}
+	return c, nil
+}
+
 func GetStatusList() []Status {
-	return []Status{Initiated, Completed, Failed, Canceled, Validated, Accounted, Rejected}
+	return []Status{Initiated, Completed, Failed, Canceled, Validated, Accounted, Rejected, Pending}
+}
+
+func GetStatusNames() []string {
+	var names []string
+	for _, st := range GetStatusList() {
+		names = append(names, st.String())
+	}
+	return names
 }
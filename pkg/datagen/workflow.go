@@ -1,41 +1,145 @@
 package datagen
 
 import (
+	"fmt"
+	"math"
+	"math/rand"
 	"mcolomerc/synth-payment-producer/pkg/config"
-	"strings"
+	"sort"
 
 	"github.com/mroth/weightedrand"
 )
 
 type Workflow struct {
 	Chooser *weightedrand.Chooser
+	graph   *graph // graph workflow model
+	rng     *rand.Rand
 }
 
-func NewWorkflowHandler(cfg config.Config) Workflow {
+// graph is the transition graph (Markov chain), each workflow is a walk from the start status to a terminal status
+type graph struct {
+	start    Status
+	terminal map[Status]bool
+	next     map[Status]*weightedrand.Chooser // Next status distribution by status
+	maxSteps int
+}
+
+func NewWorkflowHandler(cfg config.Config, seed int64) (Workflow, error) {
+	if cfg.Datagen.Model == config.ModelGraph {
+		g, err := newGraph(cfg.Datagen.Graph)
+		if err != nil {
+			return Workflow{}, err
+		}
+		return Workflow{graph: g, rng: rand.New(rand.NewSource(seed))}, nil
+	}
 	var choices []weightedrand.Choice
-	for v, k := range cfg.Datagen.Workflows {
-		s := strings.Split(v, ",")
+	// Sorted, so the same seed picks the same workflows
+	var workflows []string
+	for v := range cfg.Datagen.Workflows {
+		workflows = append(workflows, v)
+	}
+	sort.Strings(workflows)
+	for _, v := range workflows {
+		k := cfg.Datagen.Workflows[v]
 		var workflow []Status
-		for st := range s {
-			status := strings.TrimSpace(s[st])
-			workflow = append(workflow, GetStatus(status))
+		for _, st := range config.WorkflowStatuses(v) {
+			status, err := ParseStatus(st)
+			if err != nil {
+				return Workflow{}, fmt.Errorf("workflow %q: %w", v, err)
+			}
+			workflow = append(workflow, status)
 		}
 		choices = append(choices, weightedrand.NewChoice(workflow, uint(k)))
 	}
 	// Distribution by workflow
-	chooser, _ := weightedrand.NewChooser(choices...)
+	chooser, err := weightedrand.NewChooser(choices...)
+	if err != nil {
+		return Workflow{}, err
+	}
 
 	return Workflow{
 		Chooser: chooser,
+		rng:     rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+func newGraph(cfg config.GraphConfig) (*graph, error) {
+	start, err := ParseStatus(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("graph start: %w", err)
+	}
+	g := &graph{
+		start:    start,
+		terminal: map[Status]bool{},
+		next:     map[Status]*weightedrand.Chooser{},
+		maxSteps: cfg.MaxSteps,
+	}
+	for _, st := range cfg.Terminal {
+		status, err := ParseStatus(st)
+		if err != nil {
+			return nil, fmt.Errorf("graph terminal: %w", err)
+		}
+		g.terminal[status] = true
 	}
+	// Sorted, so the same seed walks the same paths
+	var states []string
+	for from := range cfg.Transitions {
+		states = append(states, from)
+	}
+	sort.Strings(states)
+	for _, from := range states {
+		status, err := ParseStatus(from)
+		if err != nil {
+			return nil, fmt.Errorf("graph transitions: %w", err)
+		}
+		var targets []string
+		for to := range cfg.Transitions[from] {
+			targets = append(targets, to)
+		}
+		sort.Strings(targets)
+		var choices []weightedrand.Choice
+		for _, to := range targets {
+			next, err := ParseStatus(to)
+			if err != nil {
+				return nil, fmt.Errorf("graph transitions from %q: %w", from, err)
+			}
+			// Probabilities as integer weights, 1e-6 resolution
+			choices = append(choices, weightedrand.NewChoice(next, uint(math.Round(cfg.Transitions[from][to]*1e6))))
+		}
+		chooser, err := weightedrand.NewChooser(choices...)
+		if err != nil {
+			return nil, fmt.Errorf("graph transitions from %q: %w", from, err)
+		}
+		g.next[status] = chooser
+	}
+	return g, nil
+}
+
+// walk returns the statuses from the start status to a terminal status, or the first maxSteps statuses
+func (g *graph) walk(rng *rand.Rand) []Status {
+	status := g.start
+	path := []Status{status}
+	for !g.terminal[status] && len(path) < g.maxSteps {
+		chooser, ok := g.next[status]
+		if !ok {
+			break
+		}
+		status = chooser.PickSource(rng).(Status)
+		path = append(path, status)
+	}
+	return path
 }
 
 /*
 *
 Randomly selects an element from some kind of list, where the chances of each element to be selected are not equal,
 but rather defined by relative "weights" (or probabilities). This is called weighted random selection.
+Not safe for concurrent use.
 *
 */
 func (w Workflow) GetWorkflow() []Status {
-	return w.Chooser.Pick().([]Status)
+	if w.graph != nil {
+		return w.graph.walk(w.rng)
+	}
+	return w.Chooser.PickSource(w.rng).([]Status)
 }
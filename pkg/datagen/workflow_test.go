@@ -0,0 +1,95 @@
+package datagen
+
+import (
+	"math"
+	"math/rand"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewGraphErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph config.GraphConfig
+		err   string
+	}{
+		{name: "start", graph: config.GraphConfig{Start: "Created"}, err: `graph start: unknown status "Created"`},
+		{name: "terminal", graph: config.GraphConfig{Start: "Initiated", Terminal: []string{"Settled"}}, err: `graph terminal: unknown status "Settled"`},
+		{name: "from", graph: config.GraphConfig{Start: "Initiated", Transitions: map[string]map[string]float64{"Created": {"Completed": 1}}}, err: `graph transitions: unknown status "Created"`},
+		{name: "to", graph: config.GraphConfig{Start: "Initiated", Transitions: map[string]map[string]float64{"Initiated": {"Settled": 1}}}, err: `graph transitions from "Initiated": unknown status "Settled"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newGraph(tt.graph); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("newGraph() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGraphWalk(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph config.GraphConfig
+		want  []Status
+	}{
+		{name: "to terminal", graph: config.GraphConfig{
+			Start:       "Initiated",
+			Terminal:    []string{"Completed"},
+			Transitions: map[string]map[string]float64{"Initiated": {"Validated": 1}, "Validated": {"Completed": 1}},
+			MaxSteps:    10,
+		}, want: []Status{Initiated, Validated, Completed}},
+		{name: "max steps", graph: config.GraphConfig{
+			Start:       "Initiated",
+			Terminal:    []string{"Completed"},
+			Transitions: map[string]map[string]float64{"Initiated": {"Validated": 1}, "Validated": {"Pending": 1}, "Pending": {"Validated": 1}},
+			MaxSteps:    5,
+		}, want: []Status{Initiated, Validated, Pending, Validated, Pending}},
+		{name: "no transitions", graph: config.GraphConfig{
+			Start:    "Initiated",
+			Terminal: []string{"Completed"},
+			MaxSteps: 10,
+		}, want: []Status{Initiated}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := newGraph(tt.graph)
+			if err != nil {
+				t.Fatalf("newGraph() error = %v", err)
+			}
+			if got := g.walk(rand.New(rand.NewSource(1))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("walk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The paths follow the transition probabilities
+func TestGraphWalkDistribution(t *testing.T) {
+	g, err := newGraph(config.GraphConfig{
+		Start:    "Initiated",
+		Terminal: []string{"Completed", "Rejected", "Failed"},
+		Transitions: map[string]map[string]float64{
+			"Initiated": {"Validated": 0.7, "Rejected": 0.3},
+			"Validated": {"Completed": 0.9, "Failed": 0.1},
+		},
+		MaxSteps: 10,
+	})
+	if err != nil {
+		t.Fatalf("newGraph() error = %v", err)
+	}
+	const n = 100000
+	counts := map[Status]int{}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		path := g.walk(rng)
+		counts[path[len(path)-1]] += 1
+	}
+	for status, want := range map[Status]float64{Completed: 0.63, Failed: 0.07, Rejected: 0.3} {
+		if got := float64(counts[status]) / n; math.Abs(got-want) > 0.01 {
+			t.Errorf("%v share = %.3f, want %.3f", status, got, want)
+		}
+	}
+}
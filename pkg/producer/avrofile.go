@@ -0,0 +1,139 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/stats"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/actgardner/gogen-avro/v10/container"
+)
+
+// Avro Object Container File, rotated at block boundaries
+type ocfFile struct {
+	file   *rotatingFile
+	writer *container.Writer
+}
+
+// AvroFileSink writes the events as Avro Object Container Files, with the payment.avsc/bank.avsc schema embedded.
+// One file per topic (payment-initiated.avro, banks.avro, ...), or payments.avro and banks.avro in single mode
+// as all the records of a container file must have the same schema.
+type AvroFileSink struct {
+	mu     sync.Mutex
+	config config.FileConfig
+	codec  container.Codec
+	block  int64
+	files  map[string]*ocfFile
+	stats  *stats.Stats
+}
+
+func NewAvroFileSink(config config.Config, sts *stats.Stats) (*AvroFileSink, error) {
+	codec := container.Codec(strings.ToLower(config.Sink.Avro.Codec))
+	switch codec {
+	case container.Null, container.Deflate, container.Snappy:
+	default:
+		return nil, fmt.Errorf("unknown avro codec %q, expected one of %v", config.Sink.Avro.Codec, []container.Codec{container.Null, container.Deflate, container.Snappy})
+	}
+	logger.With("dir", config.Sink.File.Dir).With("codec", codec).Info("Using Avro files: ")
+	return &AvroFileSink{
+		config: config.Sink.File,
+		codec:  codec,
+		block:  config.Sink.Avro.BlockRecords,
+		files:  make(map[string]*ocfFile),
+		stats:  sts,
+	}, nil
+}
+
+func (s *AvroFileSink) Produce(ctx context.Context, payment model.Payment) error {
+	topic := config.PaymentTopic(payment.Status)
+	name := topic
+	if s.config.Single {
+		name = "payments"
+	}
+	return s.write(topic, name, payment.Status, &payment)
+}
+
+func (s *AvroFileSink) ProduceBank(ctx context.Context, bank model.Bank) error {
+	return s.write("banks", "banks", "Bank", &bank)
+}
+
+func (s *AvroFileSink) write(topic string, name string, event string, record container.AvroRecord) error {
+	s.mu.Lock()
+	err := s.writeRecord(name, record)
+	s.mu.Unlock()
+	s.stats.AddDelivery(topic, event, err)
+	return err
+}
+
+func (s *AvroFileSink) writeRecord(name string, record container.AvroRecord) error {
+	f, ok := s.files[name]
+	if !ok {
+		f = &ocfFile{file: &rotatingFile{dir: s.config.Dir, name: name, ext: "avro"}}
+		s.files[name] = f
+	}
+	// Rotates once the block with the last record is written
+	if f.writer != nil && s.config.MaxBytes > 0 && f.file.written >= s.config.MaxBytes {
+		if err := f.close(); err != nil {
+			return err
+		}
+		f.file.part++
+	}
+	if f.writer == nil {
+		writer, err := container.NewWriter(f.file, s.codec, s.block, record.Schema())
+		if err != nil {
+			return err
+		}
+		f.writer = writer
+	}
+	return f.writer.WriteRecord(record)
+}
+
+func (f *ocfFile) flush() error {
+	if f.writer == nil {
+		return nil
+	}
+	if err := f.writer.Flush(); err != nil {
+		return err
+	}
+	return f.file.flush()
+}
+
+func (f *ocfFile) close() error {
+	err := f.flush()
+	if closeErr := f.file.close(); err == nil {
+		err = closeErr
+	}
+	f.writer = nil
+	return err
+}
+
+// Flush writes the pending blocks, the files are valid Avro files after Flush
+func (s *AvroFileSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.files {
+		if err := f.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AvroFileSink) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.files {
+		if err := f.close(); err != nil {
+			logger.Error("Failed to close avro file: %s", err)
+		}
+	}
+}
+
+// CreateTopics creates the output directory
+func (s *AvroFileSink) CreateTopics() error {
+	return os.MkdirAll(s.config.Dir, 0755)
+}
@@ -0,0 +1,132 @@
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/stats"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// Console formats
+const (
+	ConsoleLine = "line" // Compact line per event
+	ConsoleJson = "json" // JSON object per line, with a topic field
+)
+
+// Line color by status
+var statusColors = map[string]text.Colors{
+	"Initiated": {text.FgCyan},
+	"Pending":   {text.FgHiBlue},
+	"Validated": {text.FgBlue},
+	"Accounted": {text.FgMagenta},
+	"Completed": {text.FgGreen, text.Bold},
+	"Canceled":  {text.FgYellow},
+	"Rejected":  {text.FgRed},
+	"Failed":    {text.FgHiRed, text.Bold},
+	"Bank":      {text.FgHiBlack},
+}
+
+// ConsoleSink prints the events to stdout, to watch the payment lifecycles without any infrastructure
+type ConsoleSink struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format string
+	color  bool
+	banks  map[string]string // Bank name by id
+	stats  *stats.Stats
+}
+
+func NewConsoleSink(config config.Config, sts *stats.Stats) (*ConsoleSink, error) {
+	format := strings.ToLower(config.Sink.Console.Format)
+	if format != ConsoleLine && format != ConsoleJson {
+		return nil, fmt.Errorf("unknown console format %q, expected one of %v", config.Sink.Console.Format, []string{ConsoleLine, ConsoleJson})
+	}
+	logger.With("format", format).Info("Using console: ")
+	return &ConsoleSink{
+		out:    os.Stdout,
+		format: format,
+		color:  config.Sink.Console.Color,
+		banks:  make(map[string]string),
+		stats:  sts,
+	}, nil
+}
+
+// SetBanks sets the bank names printed instead of the source/destination ids
+func (s *ConsoleSink) SetBanks(banks []model.Bank) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bank := range banks {
+		s.banks[bank.Id] = bank.Name
+	}
+}
+
+func (s *ConsoleSink) Produce(ctx context.Context, payment model.Payment) error {
+	topic := config.PaymentTopic(payment.Status)
+	if s.format == ConsoleJson {
+		return s.writeJson(topic, payment.Status, &payment)
+	}
+	s.mu.Lock()
+	line := fmt.Sprintf("%s %-18s %-9s %s %12.2f %s %s -> %s",
+		time.UnixMilli(payment.Ts).Format("15:04:05.000"), topic, payment.Status, payment.Id,
+		payment.Amount, payment.Currency, s.bankName(payment.Source), s.bankName(payment.Destination))
+	s.mu.Unlock()
+	return s.write(topic, payment.Status, line)
+}
+
+func (s *ConsoleSink) ProduceBank(ctx context.Context, bank model.Bank) error {
+	s.SetBanks([]model.Bank{bank})
+	if s.format == ConsoleJson {
+		return s.writeJson("banks", "Bank", &bank)
+	}
+	line := fmt.Sprintf("%s %-18s %-9s %s %s (%s) v%d",
+		bankTime(bank).Local().Format("15:04:05.000"), "banks", "Bank", bank.Id, bank.Name, bank.Country, bank.Version)
+	return s.write("banks", "Bank", line)
+}
+
+func (s *ConsoleSink) bankName(id string) string {
+	if name, ok := s.banks[id]; ok {
+		return name
+	}
+	return id
+}
+
+// writeJson prints {"topic": "...", ...fields}
+func (s *ConsoleSink) writeJson(topic string, event string, value json.Marshaler) error {
+	line, err := value.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return s.write(topic, event, `{"topic":`+quote(topic)+`,`+string(line[1:]))
+}
+
+func (s *ConsoleSink) write(topic string, event string, line string) error {
+	if colors, ok := statusColors[event]; ok && s.color && s.format == ConsoleLine {
+		line = colors.Sprint(line)
+	}
+	s.mu.Lock()
+	_, err := fmt.Fprintln(s.out, line)
+	s.mu.Unlock()
+	s.stats.AddDelivery(topic, event, err)
+	return err
+}
+
+// Flush is a no-op, the events are printed unbuffered
+func (s *ConsoleSink) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (s *ConsoleSink) Close() {}
+
+// CreateTopics is a no-op
+func (s *ConsoleSink) CreateTopics() error {
+	return nil
+}
@@ -0,0 +1,109 @@
+package producer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/stats"
+	"strconv"
+	"strings"
+)
+
+// CSV columns by name, the *_name columns join the source/destination bank names
+// Values of the config.CsvColumns
+var csvColumns = map[string]func(payment model.Payment, banks map[string]string) string{
+	"topic":            func(p model.Payment, _ map[string]string) string { return config.PaymentTopic(p.Status) },
+	"id":               func(p model.Payment, _ map[string]string) string { return p.Id },
+	"ts":               func(p model.Payment, _ map[string]string) string { return strconv.FormatInt(p.Ts, 10) },
+	"date_ts":          func(p model.Payment, _ map[string]string) string { return p.Date_ts },
+	"status":           func(p model.Payment, _ map[string]string) string { return p.Status },
+	"source":           func(p model.Payment, _ map[string]string) string { return p.Source },
+	"source_name":      func(p model.Payment, banks map[string]string) string { return banks[p.Source] },
+	"destination":      func(p model.Payment, _ map[string]string) string { return p.Destination },
+	"destination_name": func(p model.Payment, banks map[string]string) string { return banks[p.Destination] },
+	"currency":         func(p model.Payment, _ map[string]string) string { return p.Currency },
+	"amount":           func(p model.Payment, _ map[string]string) string { return strconv.FormatFloat(p.Amount, 'f', 2, 64) },
+}
+
+// CsvSink writes the payment status updates as CSV files with a header row, one file per topic
+// (payment-initiated.csv, ...) or a single events.csv file.
+// The bank updates are not written, they refresh the bank names joined in the source_name/destination_name columns.
+type CsvSink struct {
+	fileSink
+	columns []string
+	comma   rune
+	banks   map[string]string // Bank name by id
+	stats   *stats.Stats
+}
+
+func NewCsvSink(cfg config.Config, sts *stats.Stats) (*CsvSink, error) {
+	comma, err := config.CsvDelimiter(cfg.Sink.Csv.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Sink.Csv.Columns) == 0 {
+		return nil, fmt.Errorf("no csv columns, expected some of %v", config.CsvColumns)
+	}
+	columns := make([]string, len(cfg.Sink.Csv.Columns))
+	for i, column := range cfg.Sink.Csv.Columns {
+		columns[i] = strings.ToLower(strings.TrimSpace(column))
+		if _, ok := csvColumns[columns[i]]; !ok {
+			return nil, fmt.Errorf("unknown csv column %q, expected one of %v", column, config.CsvColumns)
+		}
+	}
+	s := &CsvSink{
+		columns: columns,
+		comma:   comma,
+		banks:   make(map[string]string),
+		stats:   sts,
+	}
+	s.files = newFiles(cfg.Sink.File, "csv", func(w io.Writer) error {
+		return s.writeRecord(w, columns)
+	})
+	logger.With("dir", cfg.Sink.File.Dir).With("columns", columns).Info("Using CSV files: ")
+	return s, nil
+}
+
+// SetBanks sets the bank names joined in the source_name/destination_name columns
+func (s *CsvSink) SetBanks(banks []model.Bank) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bank := range banks {
+		s.banks[bank.Id] = bank.Name
+	}
+}
+
+func (s *CsvSink) Produce(ctx context.Context, payment model.Payment) error {
+	topic := config.PaymentTopic(payment.Status)
+	s.mu.Lock()
+	record := make([]string, len(s.columns))
+	for i, column := range s.columns {
+		record[i] = csvColumns[column](payment, s.banks)
+	}
+	err := s.writeRecord(s.files.get(topic), record)
+	s.mu.Unlock()
+	s.stats.AddDelivery(topic, payment.Status, err)
+	return err
+}
+
+func (s *CsvSink) ProduceBank(ctx context.Context, bank model.Bank) error {
+	s.SetBanks([]model.Bank{bank})
+	return nil
+}
+
+// writeRecord writes the record as a single Write, so that the files are not rotated in the middle of a row
+func (s *CsvSink) writeRecord(w io.Writer, record []string) error {
+	var line bytes.Buffer
+	writer := csv.NewWriter(&line)
+	writer.Comma = s.comma
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	writer.Flush()
+	_, err := w.Write(line.Bytes())
+	return err
+}
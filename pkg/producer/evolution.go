@@ -0,0 +1,69 @@
+package producer
+
+import (
+	"fmt"
+	"hash/fnv"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	v2 "mcolomerc/synth-payment-producer/pkg/avro/v2"
+	"strings"
+)
+
+// SchemaVersioned is implemented by the sinks supporting several versions of the Payment and Bank schemas,
+// the version can be switched mid-run (schema evolution)
+type SchemaVersioned interface {
+	SetSchemaVersion(version int)
+}
+
+var channels = []string{"online", "mobile", "branch", "api"}
+
+// Deterministic value from the id, so that all the status updates of a payment have the same v2 fields
+func hashId(id string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return h.Sum32()
+}
+
+// paymentV2 adds the v2 optional fields: reference and fee are null for some payments
+func paymentV2(p model.Payment) *v2.Payment {
+	h := hashId(p.Id)
+	payment := v2.NewPayment()
+	payment.Id = p.Id
+	payment.Ts = p.Ts
+	payment.Date_ts = p.Date_ts
+	payment.Destination = p.Destination
+	payment.Source = p.Source
+	payment.Currency = p.Currency
+	payment.Amount = p.Amount
+	payment.Status = p.Status
+	if h%4 != 0 {
+		payment.Reference = &v2.UnionNullString{UnionType: v2.UnionNullStringTypeEnumString, String: fmt.Sprintf("REF-%s", strings.ToUpper(p.Id[:min(8, len(p.Id))]))}
+	}
+	if h%3 != 0 {
+		fee := float64(int64(p.Amount*float64(10+h%20)/10)) / 1000 // 0.1% to 0.3% of the amount
+		payment.Fee = &v2.UnionNullDouble{UnionType: v2.UnionNullDoubleTypeEnumDouble, Double: fee}
+	}
+	payment.Channel = channels[(h/12)%uint32(len(channels))]
+	return &payment
+}
+
+// bankV2 adds the v2 optional fields
+func bankV2(b model.Bank) *v2.Bank {
+	h := hashId(b.Id)
+	bank := v2.NewBank()
+	bank.Id = b.Id
+	bank.Name = b.Name
+	bank.Country = b.Country
+	bank.Email = b.Email
+	bank.Website = b.Website
+	bank.BankCode = b.BankCode
+	bank.Bic = b.Bic
+	bank.Branch = b.Branch
+	bank.Created_ts = b.Created_ts
+	bank.Updated_ts = b.Updated_ts
+	bank.Version = b.Version
+	if h%2 == 0 {
+		bank.Lei = &v2.UnionNullString{UnionType: v2.UnionNullStringTypeEnumString, String: fmt.Sprintf("%020X", uint64(h)*2654435761)}
+	}
+	bank.Active = h%10 != 0
+	return &bank
+}
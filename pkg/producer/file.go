@@ -0,0 +1,199 @@
+package producer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Name of the file used for all the topics when config.FileConfig.Single is set
+const singleFile = "events"
+
+// rotatingFile writes to <dir>/<name>.<ext>[.gz], continues in <name>-1.<ext>, <name>-2.<ext>, ...
+// once maxBytes (uncompressed) are written, if maxBytes > 0.
+type rotatingFile struct {
+	dir      string
+	name     string
+	ext      string
+	gzip     bool
+	maxBytes int64
+	header   func(w io.Writer) error // written at the beginning of each file, optional
+
+	file    *os.File
+	gz      *gzip.Writer
+	buf     *bufio.Writer
+	written int64
+	part    int
+}
+
+func (r *rotatingFile) path() string {
+	name := r.name
+	if r.part > 0 {
+		name = fmt.Sprintf("%s-%d", r.name, r.part)
+	}
+	path := filepath.Join(r.dir, name+"."+r.ext)
+	if r.gzip {
+		path += ".gz"
+	}
+	return path
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.Create(r.path())
+	if err != nil {
+		return err
+	}
+	r.file = file
+	var w io.Writer = file
+	if r.gzip {
+		r.gz = gzip.NewWriter(file)
+		w = r.gz
+	}
+	r.buf = bufio.NewWriter(w)
+	r.written = 0
+	if r.header != nil {
+		return r.header(r)
+	}
+	return nil
+}
+
+// Write writes p to the current file, opening or rotating the file if needed
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	if r.file != nil && r.maxBytes > 0 && r.written > 0 && r.written+int64(len(p)) > r.maxBytes {
+		if err := r.close(); err != nil {
+			return 0, err
+		}
+		r.part++
+	}
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.buf.Write(p)
+	r.written += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) flush() error {
+	if r.file == nil {
+		return nil
+	}
+	if err := r.buf.Flush(); err != nil {
+		return err
+	}
+	if r.gz != nil {
+		return r.gz.Flush()
+	}
+	return nil
+}
+
+func (r *rotatingFile) close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.buf.Flush()
+	if r.gz != nil {
+		if gzErr := r.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file, r.gz, r.buf = nil, nil, nil
+	return err
+}
+
+// files keeps one rotating file per topic, or a single file for all the topics.
+// Not safe for concurrent use, the sinks lock around it.
+type files struct {
+	config config.FileConfig
+	ext    string
+	header func(w io.Writer) error
+	files  map[string]*rotatingFile
+}
+
+func newFiles(cfg config.FileConfig, ext string, header func(w io.Writer) error) *files {
+	return &files{
+		config: cfg,
+		ext:    ext,
+		header: header,
+		files:  make(map[string]*rotatingFile),
+	}
+}
+
+// get returns the file for the topic
+func (f *files) get(topic string) *rotatingFile {
+	name := topic
+	if f.config.Single {
+		name = singleFile
+	}
+	file, ok := f.files[name]
+	if !ok {
+		file = &rotatingFile{
+			dir:      f.config.Dir,
+			name:     name,
+			ext:      f.ext,
+			gzip:     f.config.Gzip,
+			maxBytes: f.config.MaxBytes,
+			header:   f.header,
+		}
+		f.files[name] = file
+	}
+	return file
+}
+
+func (f *files) mkdir() error {
+	return os.MkdirAll(f.config.Dir, 0755)
+}
+
+func (f *files) flush() error {
+	for _, file := range f.files {
+		if err := file.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *files) close() error {
+	var err error
+	for _, file := range f.files {
+		if closeErr := file.close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// fileSink implements Flush, Close and CreateTopics for the sinks writing to files
+type fileSink struct {
+	mu    sync.Mutex
+	files *files
+}
+
+func (s *fileSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files.flush()
+}
+
+func (s *fileSink) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.files.close(); err != nil {
+		logger.Error("Failed to close files: %s", err)
+	}
+}
+
+// CreateTopics creates the output directory
+func (s *fileSink) CreateTopics() error {
+	return s.files.mkdir()
+}
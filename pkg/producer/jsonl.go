@@ -0,0 +1,53 @@
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/stats"
+)
+
+// JsonlSink writes the events as JSON Lines, one file per topic (payment-initiated.jsonl, banks.jsonl, ...)
+// or a single events.jsonl file with a topic field.
+type JsonlSink struct {
+	fileSink
+	stats *stats.Stats
+}
+
+func NewJsonlSink(config config.Config, sts *stats.Stats) *JsonlSink {
+	logger.With("dir", config.Sink.File.Dir).Info("Using JSON Lines files: ")
+	return &JsonlSink{
+		fileSink: fileSink{files: newFiles(config.Sink.File, "jsonl", nil)},
+		stats:    sts,
+	}
+}
+
+func (s *JsonlSink) Produce(ctx context.Context, payment model.Payment) error {
+	return s.write(config.PaymentTopic(payment.Status), payment.Status, &payment)
+}
+
+func (s *JsonlSink) ProduceBank(ctx context.Context, bank model.Bank) error {
+	return s.write("banks", "Bank", &bank)
+}
+
+func (s *JsonlSink) write(topic string, event string, value json.Marshaler) error {
+	line, err := value.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if s.files.config.Single { // {"topic": "...", ...fields}
+		line = append([]byte(`{"topic":`+quote(topic)+`,`), line[1:]...)
+	}
+	line = append(line, '\n')
+	s.mu.Lock()
+	_, err = s.files.get(topic).Write(line)
+	s.mu.Unlock()
+	s.stats.AddDelivery(topic, event, err)
+	return err
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
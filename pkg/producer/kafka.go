@@ -0,0 +1,292 @@
+// Example function-based Apache Kafka producer
+package producer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/stats"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry"
+	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde"
+	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde/avro"
+	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde/jsonschema"
+	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde/protobuf"
+)
+
+// serializer encodes the message value for the topic
+type serializer interface {
+	Serialize(topic string, msg interface{}) ([]byte, error)
+}
+
+// jsonSerializer encodes the messages as schemaless JSON, without Schema Registry
+type jsonSerializer struct{}
+
+func (jsonSerializer) Serialize(topic string, msg interface{}) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+// KafkaProducer is the Sink backend writing the events to Kafka, encoded with the
+// Schema Registry Avro, JSON Schema or Protobuf serializer, or as plain JSON (config.Kafka.Serialization).
+type KafkaProducer struct {
+	kafka          *kafka.Producer
+	schemaRegistry schemaregistry.Client // nil for plain JSON
+	ser            serializer
+	config         config.Config
+	events         chan struct{} // closed when the events channel is drained
+	version        atomic.Int32  // Schema version of the events, avro/payment.avsc (1) or avro/payment_v2.avsc (2)
+}
+
+// NewKafkaProducer creates the producer, the delivery reports are counted in sts
+func NewKafkaProducer(config config.Config, sts *stats.Stats) (*KafkaProducer, error) {
+	logger.With("bootstrap.server", config.Kafka.BootstrapServers).With("serialization", config.Kafka.Serialization).Info("Using: ")
+	kConfig := &kafka.ConfigMap{
+		"bootstrap.servers": config.Kafka.BootstrapServers,
+		"client.id":         config.Kafka.ClientId,
+		"sasl.mechanisms":   config.Kafka.SaslMechanisms,
+		"security.protocol": config.Kafka.SecurityProtocol,
+		"sasl.username":     config.Kafka.SaslUsername,
+		"sasl.password":     config.Kafka.SaslPassword,
+	}
+	for k, v := range config.Kafka.ConfigMap {
+		kConfig.SetKey(k, v)
+	}
+	vnum, vstr := kafka.LibraryVersion()
+	logger.Info("Library Version: %s (0x%x)", vstr, vnum)
+	logger.Info("Link Info:       %s", kafka.LibrdkafkaLinkInfo)
+
+	producer, err := kafka.NewProducer(kConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+
+	client, ser, err := newSerializer(config)
+	if err != nil {
+		producer.Close()
+		return nil, err
+	}
+	// Listen to all the events on the default events channel
+	events := make(chan struct{})
+	go func() {
+		defer close(events)
+		for e := range producer.Events() {
+			switch ev := e.(type) {
+			case *kafka.Message:
+				m := ev
+				event, _ := m.Opaque.(string) // Status or Bank
+				sts.AddDelivery(*m.TopicPartition.Topic, event, m.TopicPartition.Error)
+				if m.TopicPartition.Error != nil {
+					logger.Info("Delivery failed: %v", m.TopicPartition.Error)
+				} else {
+					logger.Info("Kafka Producer: Delivered message to topic %s [%d] at offset %v",
+						*m.TopicPartition.Topic, m.TopicPartition.Partition, m.TopicPartition.Offset)
+				}
+			case kafka.Error:
+				fmt.Printf("Error: %v\n", ev)
+			case *kafka.Stats:
+				// https://github.com/confluentinc/librdkafka/blob/master/STATISTICS.md
+				var stats map[string]interface{}
+				json.Unmarshal([]byte(e.String()), &stats)
+				logger.Info("------librdkafka---------")
+				logger.Info("Stats: %v messages (%v bytes) produced",
+					stats["txmsgs"], stats["txmsg_bytes"])
+				logger.Info(" %v messages ", stats["msg_cnt"])
+				logger.Info(" %v number of bytes received from Kafka brokers", stats["rx_bytes"])
+				mb := stats["txmsg_bytes"]
+				mbb := mb.(float64) / 1024 / 1024
+				logger.Info("%v requests sent  (%v bytes / %v Mbytes) bytes transmitted to Kafka brokers\n",
+					stats["tx"], stats["tx_bytes"], mbb)
+
+			default:
+				logger.Info("Ignored event: %s\n", ev)
+			}
+		}
+	}()
+	p := &KafkaProducer{
+		kafka:          producer,
+		schemaRegistry: client,
+		ser:            ser,
+		config:         config,
+		events:         events,
+	}
+	p.version.Store(int32(config.Schema.Version))
+	return p, nil
+}
+
+// SetSchemaVersion switches the schema of the next events
+func (p *KafkaProducer) SetSchemaVersion(version int) {
+	p.version.Store(int32(version))
+}
+
+// newSerializer creates the serializer, and the Schema Registry client if the serialization uses it
+func newSerializer(cfg config.Config) (schemaregistry.Client, serializer, error) {
+	if cfg.Kafka.Serialization == config.SerializationJson {
+		return nil, jsonSerializer{}, nil
+	}
+	client, err := schemaregistry.NewClient(schemaregistry.NewConfigWithAuthentication(
+		cfg.SchemaRegistry.Endpoint,
+		cfg.SchemaRegistry.ApiKey,
+		cfg.SchemaRegistry.ApiSecret))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create schema registry client: %w", err)
+	}
+	strategy, err := subjectNameStrategy(cfg.SchemaRegistry.SubjectNameStrategy)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Registers the schemas, or looks up the pre-registered schemas (latest version)
+	configure := func(conf *serde.SerializerConfig) {
+		conf.AutoRegisterSchemas = cfg.SchemaRegistry.AutoRegister
+		conf.UseLatestVersion = cfg.SchemaRegistry.UseLatestVersion
+	}
+	var ser serializer
+	switch cfg.Kafka.Serialization {
+	case config.SerializationAvro:
+		conf := avro.NewSerializerConfig()
+		configure(&conf.SerializerConfig)
+		var aser *avro.SpecificSerializer
+		if aser, err = avro.NewSpecificSerializer(client, serde.ValueSerde, conf); err == nil {
+			aser.SubjectNameStrategy = strategy
+		}
+		ser = aser
+	case config.SerializationJsonSchema:
+		// The JSON Schema is reflected from the types generated from the .avsc files
+		conf := jsonschema.NewSerializerConfig()
+		configure(&conf.SerializerConfig)
+		var jser *jsonschema.Serializer
+		if jser, err = jsonschema.NewSerializer(client, serde.ValueSerde, conf); err == nil {
+			jser.SubjectNameStrategy = strategy
+		}
+		ser = jser
+	case config.SerializationProtobuf:
+		conf := protobuf.NewSerializerConfig()
+		configure(&conf.SerializerConfig)
+		var pser *protobuf.Serializer
+		if pser, err = protobuf.NewSerializer(client, serde.ValueSerde, conf); err == nil {
+			pser.SubjectNameStrategy = strategy
+		}
+		ser = protobufSerializer{ser: pser}
+	default:
+		err = fmt.Errorf("unknown serialization %q", cfg.Kafka.Serialization)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create serializer: %w", err)
+	}
+	return client, ser, nil
+}
+
+func (p *KafkaProducer) Produce(ctx context.Context, payment model.Payment) error {
+	// Get topic
+	topic := config.PaymentTopic(payment.Status)
+	// Serialize Payment
+	var msg interface{} = &payment
+	if p.version.Load() == 2 {
+		msg = paymentV2(payment)
+	}
+	payload, err := p.ser.Serialize(topic, msg)
+	if err != nil {
+		return fmt.Errorf("failed to serialize payment %s: %w", payment.Id, err)
+	}
+	// Produce Payment status update
+	err = p.kafka.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            []byte(payment.Id),
+		Value:          payload,
+		Timestamp:      time.UnixMilli(payment.Ts), // Event time, simulated in backfill mode
+		TimestampType:  kafka.TimestampCreateTime,
+		Headers:        []kafka.Header{{Key: payment.Id, Value: []byte(payment.Status)}},
+		Opaque:         payment.Status,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to produce payment %s to %s: %w", payment.Id, topic, err)
+	}
+	return nil
+}
+
+func (p *KafkaProducer) ProduceBank(ctx context.Context, bank model.Bank) error {
+	// Get topic
+	topic := "banks"
+	// Serialize Bank
+	var msg interface{} = &bank
+	if p.version.Load() == 2 {
+		msg = bankV2(bank)
+	}
+	payload, err := p.ser.Serialize(topic, msg)
+	if err != nil {
+		return fmt.Errorf("failed to serialize bank %s: %w", bank.Id, err)
+	}
+	// Produce Payment status update
+	err = p.kafka.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            []byte(bank.Id),
+		Value:          payload,
+		Timestamp:      bankTime(bank),
+		TimestampType:  kafka.TimestampCreateTime,
+		Headers:        []kafka.Header{{Key: bank.Id, Value: []byte(bank.BankCode)}},
+		Opaque:         "Bank",
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to produce bank %s to %s: %w", bank.Id, topic, err)
+	}
+	return nil
+}
+
+// Close closes the producer, once the pending delivery reports are counted
+func (p *KafkaProducer) Close() {
+	p.kafka.Close()
+	<-p.events
+}
+
+func (p *KafkaProducer) Flush(ctx context.Context) error {
+	for {
+		remaining := p.kafka.Flush(1000)
+		if remaining == 0 {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%d outstanding messages not flushed: %w", remaining, ctx.Err())
+		}
+		logger.Info(" Still waiting to flush outstanding messages ")
+	}
+}
+
+func (p *KafkaProducer) CreateTopics() error {
+	// Create topics
+	topics := p.config.Kafka.Topics
+	// Create topics
+	admin, err := kafka.NewAdminClientFromProducer(p.kafka)
+	if err != nil {
+		return fmt.Errorf("failed to create admin client: %w", err)
+	}
+	defer admin.Close()
+	var topicsSpec []kafka.TopicSpecification
+	for k, v := range topics {
+		topicsSpec = append(topicsSpec, createTopic(k, v, 3))
+	}
+	// Contexts are used to abort or limit the amount of time
+	// the Admin call blocks waiting for a result.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results, err := admin.CreateTopics(ctx, topicsSpec)
+	if err != nil {
+		return fmt.Errorf("failed to create topics: %w", err)
+	}
+	for _, result := range results {
+		logger.Info("%s", result)
+	}
+	return nil
+}
+
+func createTopic(topic string, numParts int, replicationFactor int) kafka.TopicSpecification {
+	return kafka.TopicSpecification{
+		Topic:             topic,
+		NumPartitions:     numParts,
+		ReplicationFactor: replicationFactor}
+}
@@ -0,0 +1,234 @@
+package producer
+
+import (
+	"context"
+	"fmt"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/stats"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+)
+
+// Payment status update row
+type paymentRow struct {
+	Id          string  `parquet:"id"`
+	Ts          int64   `parquet:"ts,timestamp(millisecond)"`
+	Date_ts     string  `parquet:"date_ts"`
+	Destination string  `parquet:"destination"`
+	Source      string  `parquet:"source"`
+	Currency    string  `parquet:"currency,dict"`
+	Amount      float64 `parquet:"amount"`
+	Status      string  `parquet:"status,dict"`
+}
+
+// Bank snapshot row
+type bankRow struct {
+	Id         string `parquet:"id"`
+	Name       string `parquet:"name"`
+	Country    string `parquet:"country,dict"`
+	Email      string `parquet:"email"`
+	Website    string `parquet:"website"`
+	BankCode   string `parquet:"bankCode"`
+	Bic        string `parquet:"bic"`
+	Branch     string `parquet:"branch"`
+	Created_ts string `parquet:"created_ts"`
+	Updated_ts string `parquet:"updated_ts"`
+	Version    int32  `parquet:"version"`
+}
+
+// Parquet file of a partition, rotated at row group boundaries
+type parquetFile[T any] struct {
+	date   string // Event date of the partition
+	file   *rotatingFile
+	writer *parquet.GenericWriter[T]
+}
+
+// Parquet files by partition directory.
+// The files of a date are closed once the event time is past the next date, the late rows continue in a new part.
+type partitions[T any] struct {
+	config  config.FileConfig
+	options []parquet.WriterOption
+	files   map[string]*parquetFile[T]
+	latest  string // Latest event date
+}
+
+func (p *partitions[T]) write(dir string, date string, row T) error {
+	if date > p.latest {
+		p.latest = date
+		if err := p.closeBefore(date); err != nil {
+			return err
+		}
+	}
+	f, ok := p.files[dir]
+	if !ok {
+		f = &parquetFile[T]{date: date, file: &rotatingFile{dir: dir, name: "part", ext: "parquet"}}
+		p.files[dir] = f
+	}
+	if f.writer == nil {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		f.writer = parquet.NewGenericWriter[T](f.file, p.options...)
+	}
+	if _, err := f.writer.Write([]T{row}); err != nil {
+		return err
+	}
+	// Rotates once the row groups written reach the max size
+	if p.config.MaxBytes > 0 && f.file.written >= p.config.MaxBytes {
+		return f.close()
+	}
+	return nil
+}
+
+// closeBefore closes the files of the dates before the day preceding date, the events can be late by up to a day
+func (p *partitions[T]) closeBefore(date string) error {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil // Not a date, e.g. an unparsable bank update time
+	}
+	cutoff := day.AddDate(0, 0, -1).Format("2006-01-02")
+	for _, f := range p.files {
+		if f.writer != nil && f.date < cutoff {
+			if err := f.close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// close writes the footer and closes the file, the next rows are written to the next part
+func (f *parquetFile[T]) close() error {
+	if f.writer == nil {
+		return nil
+	}
+	err := f.writer.Close() // Writes the footer
+	if closeErr := f.file.close(); err == nil {
+		err = closeErr
+	}
+	f.writer = nil
+	f.file.part++
+	f.file.written = 0
+	return err
+}
+
+// flush writes the buffered rows as row groups
+func (p *partitions[T]) flush() error {
+	for _, f := range p.files {
+		if f.writer == nil {
+			continue
+		}
+		if err := f.writer.Flush(); err != nil {
+			return err
+		}
+		if err := f.file.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *partitions[T]) close() error {
+	var err error
+	for _, f := range p.files {
+		if closeErr := f.close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// ParquetSink writes the payment status updates as Parquet files partitioned by status and event date (from Payment.Ts):
+// <dir>/payments/status=<status>/date=<yyyy-mm-dd>/part.parquet
+// and the bank snapshots partitioned by update date: <dir>/banks/date=<yyyy-mm-dd>/part.parquet
+type ParquetSink struct {
+	mu       sync.Mutex
+	dir      string
+	payments *partitions[paymentRow]
+	banks    *partitions[bankRow]
+	stats    *stats.Stats
+}
+
+func NewParquetSink(config config.Config, sts *stats.Stats) (*ParquetSink, error) {
+	var codec compress.Codec
+	switch strings.ToLower(config.Sink.Parquet.Compression) {
+	case "none", "uncompressed":
+		codec = &parquet.Uncompressed
+	case "snappy":
+		codec = &parquet.Snappy
+	case "gzip":
+		codec = &parquet.Gzip
+	case "zstd":
+		codec = &parquet.Zstd
+	default:
+		return nil, fmt.Errorf("unknown parquet compression %q, expected one of %v", config.Sink.Parquet.Compression, []string{"none", "snappy", "gzip", "zstd"})
+	}
+	options := []parquet.WriterOption{
+		parquet.Compression(codec),
+		parquet.MaxRowsPerRowGroup(config.Sink.Parquet.RowGroupRows),
+		parquet.CreatedBy("synth-payment-producer", "", ""),
+		parquet.WriteBufferSize(0), // rotatingFile is buffered, counts the row group bytes as they are written
+	}
+	logger.With("dir", config.Sink.File.Dir).With("compression", codec.String()).Info("Using Parquet files: ")
+	return &ParquetSink{
+		dir:      config.Sink.File.Dir,
+		payments: &partitions[paymentRow]{config: config.Sink.File, options: options, files: make(map[string]*parquetFile[paymentRow])},
+		banks:    &partitions[bankRow]{config: config.Sink.File, options: options, files: make(map[string]*parquetFile[bankRow])},
+		stats:    sts,
+	}, nil
+}
+
+func (s *ParquetSink) Produce(ctx context.Context, payment model.Payment) error {
+	date := time.UnixMilli(payment.Ts).UTC().Format("2006-01-02")
+	dir := filepath.Join(s.dir, "payments", "status="+payment.Status, "date="+date)
+	s.mu.Lock()
+	err := s.payments.write(dir, date, paymentRow(payment))
+	s.mu.Unlock()
+	s.stats.AddDelivery(config.PaymentTopic(payment.Status), payment.Status, err)
+	return err
+}
+
+func (s *ParquetSink) ProduceBank(ctx context.Context, bank model.Bank) error {
+	date := bank.Updated_ts
+	if updated, err := time.Parse(time.RFC3339, bank.Updated_ts); err == nil {
+		date = updated.UTC().Format("2006-01-02")
+	}
+	dir := filepath.Join(s.dir, "banks", "date="+date)
+	s.mu.Lock()
+	err := s.banks.write(dir, date, bankRow(bank))
+	s.mu.Unlock()
+	s.stats.AddDelivery("banks", "Bank", err)
+	return err
+}
+
+// Flush writes the pending rows as row groups, the Parquet files are only readable once closed (footer)
+func (s *ParquetSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.payments.flush(); err != nil {
+		return err
+	}
+	return s.banks.flush()
+}
+
+func (s *ParquetSink) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, err := range []error{s.payments.close(), s.banks.close()} {
+		if err != nil {
+			logger.Error("Failed to close parquet file: %s", err)
+		}
+	}
+}
+
+// CreateTopics creates the output directory, the partitions are created on the first row
+func (s *ParquetSink) CreateTopics() error {
+	return os.MkdirAll(s.dir, 0755)
+}
@@ -1,197 +1,113 @@
-// Example function-based Apache Kafka producer
 package producer
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-
 	model "mcolomerc/synth-payment-producer/pkg/avro"
 	"mcolomerc/synth-payment-producer/pkg/config"
-	"os"
+	"mcolomerc/synth-payment-producer/pkg/stats"
 	"strings"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
-	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry"
-	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde"
-	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde/avro"
 	"github.com/m-mizutani/zlog"
-	"github.com/m-mizutani/zlog/filter"
 )
 
 var logger *zlog.Logger
 
-type Producer struct {
-	kafka          *kafka.Producer
-	schemaRegistry *schemaregistry.Client
-	ser            *avro.SpecificSerializer
-	config         config.Config
+// Sink is the destination of the generated payment status updates and bank updates.
+type Sink interface {
+	// Produce sends a payment status update, the sinks sending requests give up when the context is done
+	Produce(ctx context.Context, payment model.Payment) error
+	// ProduceBank sends a bank update
+	ProduceBank(ctx context.Context, bank model.Bank) error
+	// Flush waits for outstanding events to be written, until the context is done
+	Flush(ctx context.Context) error
+	// Close releases the sink resources
+	Close()
+	// CreateTopics creates the destinations (topics, files, ...) if they don't exist
+	CreateTopics() error
 }
 
-func NewProducer(config config.Config) Producer {
-	logger = zlog.New(zlog.WithFilters(filter.Tag()))
+// BankAware is implemented by the sinks that need the generated banks before the first bank update,
+// e.g. to join the bank names
+type BankAware interface {
+	SetBanks(banks []model.Bank)
+}
 
-	kConfig := &kafka.ConfigMap{
-		"bootstrap.servers": config.Kafka.BootstrapServers,
-		"client.id":         config.Kafka.ClientId,
-		"sasl.mechanisms":   config.Kafka.SaslMechanisms,
-		"security.protocol": config.Kafka.SecurityProtocol,
-		"sasl.username":     config.Kafka.SaslUsername,
-		"sasl.password":     config.Kafka.SaslPassword,
-	}
-	for k, v := range config.Kafka.ConfigMap {
-		kConfig.SetKey(k, v)
-	}
-	vnum, vstr := kafka.LibraryVersion()
-	logger.Info("Library Version: %s (0x%x)", vstr, vnum)
-	logger.Info("Link Info:       %s", kafka.LibrdkafkaLinkInfo)
+const (
+	SinkKafka   = "kafka"
+	SinkJsonl   = "jsonl"
+	SinkAvro    = "avro"
+	SinkParquet = "parquet"
+	SinkCsv     = "csv"
+	SinkConsole = "console"
+	SinkWebhook = "webhook"
+)
 
-	producer, err := kafka.NewProducer(kConfig)
-	if err != nil {
-		logger.Info("Failed to create producer: %s", err)
-		os.Exit(1)
-	}
+// NewProducer builds the Sink backend selected by config.Sink.Type, the sink counts the delivered and failed events in sts
+func NewProducer(config config.Config, sts *stats.Stats) (Sink, error) {
+	logger = config.Logger()
 
-	client, err := schemaregistry.NewClient(schemaregistry.NewConfigWithAuthentication(
-		config.SchemaRegistry.Endpoint,
-		config.SchemaRegistry.ApiKey,
-		config.SchemaRegistry.ApiSecret))
-	if err != nil {
-		logger.Info("Failed to create schema registry client: %s\n", err)
-		os.Exit(1)
-	}
-	ser, err := avro.NewSpecificSerializer(client, serde.ValueSerde, avro.NewSerializerConfig())
-	if err != nil {
-		logger.Info("Failed to create serializer: %s\n", err)
-		os.Exit(1)
-	}
-	// Listen to all the events on the default events channel
-	go func() {
-		for e := range producer.Events() {
-			switch ev := e.(type) {
-			case *kafka.Message:
-				m := ev
-				if m.TopicPartition.Error != nil {
-					logger.Info("Delivery failed: %v", m.TopicPartition.Error)
-				} else {
-					logger.Info("Kafka Producer: Delivered message to topic %s [%d] at offset %v",
-						*m.TopicPartition.Topic, m.TopicPartition.Partition, m.TopicPartition.Offset)
-				}
-			case kafka.Error:
-				fmt.Printf("Error: %v\n", ev)
-			case *kafka.Stats:
-				// https://github.com/confluentinc/librdkafka/blob/master/STATISTICS.md
-				var stats map[string]interface{}
-				json.Unmarshal([]byte(e.String()), &stats)
-				logger.Info("------librdkafka---------")
-				logger.Info("Stats: %v messages (%v bytes) produced",
-					stats["txmsgs"], stats["txmsg_bytes"])
-				logger.Info(" %v messages ", stats["msg_cnt"])
-				logger.Info(" %v number of bytes received from Kafka brokers", stats["rx_bytes"])
-				mb := stats["txmsg_bytes"]
-				mbb := mb.(float64) / 1024 / 1024
-				logger.Info("%v requests sent  (%v bytes / %v Mbytes) bytes transmitted to Kafka brokers\n",
-					stats["tx"], stats["tx_bytes"], mbb)
-
-			default:
-				logger.Info("Ignored event: %s\n", ev)
-			}
+	switch strings.ToLower(config.Sink.Type) {
+	case SinkKafka:
+		p, err := NewKafkaProducer(config, sts)
+		if err != nil {
+			return nil, err
 		}
-	}()
-	return Producer{
-		kafka:          producer,
-		schemaRegistry: &client,
-		ser:            ser,
-		config:         config,
-	}
-}
-
-func (p Producer) Produce(payment model.Payment) {
-	// Get topic
-	topic := fmt.Sprintf("payment-%s", strings.ToLower(payment.Status))
-	// Serialize Payment
-	payload, err := p.ser.Serialize(topic, &payment)
-	if err != nil {
-		logger.Info("Failed to serialize payload: %s\n", err)
-		os.Exit(1)
-	}
-	// Produce Payment status update
-	err = p.kafka.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
-		Key:            []byte(payment.Id),
-		Value:          payload,
-		Headers:        []kafka.Header{{Key: payment.Id, Value: []byte(payment.Status)}},
-	}, nil)
-	if err != nil {
-		logger.Info("Failed to produce message: %s\n", err)
-		os.Exit(1)
-	}
-}
-
-func (p Producer) ProduceBank(bank model.Bank) {
-	// Get topic
-	topic := "banks"
-	// Serialize Payment
-	payload, err := p.ser.Serialize(topic, &bank)
-	if err != nil {
-		logger.Info("Failed to serialize payload: %s\n", err)
-		os.Exit(1)
-	}
-	// Produce Payment status update
-	err = p.kafka.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
-		Key:            []byte(bank.Id),
-		Value:          payload,
-		Headers:        []kafka.Header{{Key: bank.Id, Value: []byte(bank.BankCode)}},
-	}, nil)
-	if err != nil {
-		logger.Info("Failed to produce message: %s\n", err)
-		os.Exit(1)
+		return p, nil
+	case SinkJsonl:
+		return NewJsonlSink(config, sts), nil
+	case SinkAvro:
+		s, err := NewAvroFileSink(config, sts)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case SinkParquet:
+		s, err := NewParquetSink(config, sts)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case SinkCsv:
+		s, err := NewCsvSink(config, sts)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case SinkConsole:
+		s, err := NewConsoleSink(config, sts)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case SinkWebhook:
+		s, err := NewWebhookSink(config, sts)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", config.Sink.Type)
 	}
 }
 
-func (p Producer) Close() {
-	p.kafka.Close()
-}
-
-func (p Producer) Flush() {
-	for p.kafka.Flush(10000) > 0 {
-		logger.Info(" Still waiting to flush outstanding messages ")
+// IsRetriable reports whether a failed produce can be retried, e.g. the Kafka producer queue is full
+func IsRetriable(err error) bool {
+	var kerr kafka.Error
+	if errors.As(err, &kerr) {
+		return kerr.Code() == kafka.ErrQueueFull || kerr.IsRetriable()
 	}
+	return false
 }
 
-func (p Producer) CreateTopics() {
-	// Create topics
-	topics := p.config.Kafka.Topics
-	// Create topics
-	admin, err := kafka.NewAdminClientFromProducer(p.kafka)
+// bankTime returns the update time of the bank, simulated in backfill mode, or the current time if it can't be parsed
+func bankTime(bank model.Bank) time.Time {
+	ts, err := time.Parse(time.RFC3339, bank.Updated_ts)
 	if err != nil {
-		logger.Info("Failed to create admin client: %s\n", err)
-		os.Exit(1)
-	}
-	var topicsSpec []kafka.TopicSpecification
-	for k, v := range topics {
-		topicsSpec = append(topicsSpec, createTopic(k, v, 3))
+		return time.Now()
 	}
-	// Contexts are used to abort or limit the amount of time
-	// the Admin call blocks waiting for a result.
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	results, err := admin.CreateTopics(ctx, topicsSpec)
-	if err != nil {
-		logger.Info("Failed to create topics: %s\n", err)
-		os.Exit(1)
-	}
-	for _, result := range results {
-		logger.Info("%s", result)
-	}
-	return
-}
-
-func createTopic(topic string, numParts int, replicationFactor int) kafka.TopicSpecification {
-	return kafka.TopicSpecification{
-		Topic:             topic,
-		NumPartitions:     numParts,
-		ReplicationFactor: replicationFactor}
+	return ts
 }
@@ -0,0 +1,47 @@
+package producer
+
+import (
+	"fmt"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	pb "mcolomerc/synth-payment-producer/pkg/proto"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde/protobuf"
+)
+
+// protobufSerializer converts the Avro generated types to the Protobuf generated types (same fields)
+// before the Schema Registry Protobuf serializer
+type protobufSerializer struct {
+	ser *protobuf.Serializer
+}
+
+func (s protobufSerializer) Serialize(topic string, msg interface{}) ([]byte, error) {
+	switch m := msg.(type) {
+	case *model.Payment:
+		return s.ser.Serialize(topic, &pb.Payment{
+			Id:          m.Id,
+			Ts:          m.Ts,
+			DateTs:      m.Date_ts,
+			Destination: m.Destination,
+			Source:      m.Source,
+			Currency:    m.Currency,
+			Amount:      m.Amount,
+			Status:      m.Status,
+		})
+	case *model.Bank:
+		return s.ser.Serialize(topic, &pb.Bank{
+			Id:        m.Id,
+			Name:      m.Name,
+			Country:   m.Country,
+			Email:     m.Email,
+			Website:   m.Website,
+			BankCode:  m.BankCode,
+			Bic:       m.Bic,
+			Branch:    m.Branch,
+			CreatedTs: m.Created_ts,
+			UpdatedTs: m.Updated_ts,
+			Version:   m.Version,
+		})
+	default:
+		return nil, fmt.Errorf("no protobuf message for %T", msg)
+	}
+}
@@ -0,0 +1,85 @@
+package producer
+
+import (
+	"encoding/json"
+	"fmt"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"regexp"
+	"strings"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry"
+	"github.com/confluentinc/confluent-kafka-go/v2/schemaregistry/serde"
+)
+
+// subjectNameStrategy returns the Schema Registry subject naming function, only TopicNameStrategy is built in the serde
+func subjectNameStrategy(strategy string) (serde.SubjectNameStrategyFunc, error) {
+	switch strategy {
+	case config.SubjectTopicName:
+		return serde.TopicNameStrategy, nil
+	case config.SubjectRecordName: // <record>
+		return func(topic string, serdeType serde.Type, schema schemaregistry.SchemaInfo) (string, error) {
+			return recordName(schema)
+		}, nil
+	case config.SubjectTopicRecordName: // <topic>-<record>
+		return func(topic string, serdeType serde.Type, schema schemaregistry.SchemaInfo) (string, error) {
+			name, err := recordName(schema)
+			if err != nil {
+				return "", err
+			}
+			return topic + "-" + name, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown subject name strategy %q", strategy)
+	}
+}
+
+var (
+	protoPackage = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+	protoMessage = regexp.MustCompile(`(?m)^\s*message\s+(\w+)`)
+)
+
+// recordName returns the fully qualified name of the record of the schema
+func recordName(schema schemaregistry.SchemaInfo) (string, error) {
+	switch schema.SchemaType {
+	case "", "AVRO":
+		var record struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(schema.Schema), &record); err != nil || len(record.Name) == 0 {
+			return "", fmt.Errorf("no record name in avro schema")
+		}
+		if len(record.Namespace) == 0 || strings.Contains(record.Name, ".") {
+			return record.Name, nil
+		}
+		return record.Namespace + "." + record.Name, nil
+	case "JSON":
+		// title, or the definition referenced by the root ("#/$defs/Payment") of the reflected schemas
+		var root struct {
+			Title string `json:"title"`
+			Ref   string `json:"$ref"`
+		}
+		if err := json.Unmarshal([]byte(schema.Schema), &root); err != nil {
+			return "", fmt.Errorf("invalid json schema: %w", err)
+		}
+		if len(root.Title) > 0 {
+			return root.Title, nil
+		}
+		if i := strings.LastIndex(root.Ref, "/"); i >= 0 && i < len(root.Ref)-1 {
+			return root.Ref[i+1:], nil
+		}
+		return "", fmt.Errorf("no record name in json schema")
+	case "PROTOBUF":
+		// First message of the file, the payment.proto and bank.proto files define a single message
+		message := protoMessage.FindStringSubmatch(schema.Schema)
+		if message == nil {
+			return "", fmt.Errorf("no message in protobuf schema")
+		}
+		if pkg := protoPackage.FindStringSubmatch(schema.Schema); pkg != nil {
+			return pkg[1] + "." + message[1], nil
+		}
+		return message[1], nil
+	default:
+		return "", fmt.Errorf("unknown schema type %q", schema.SchemaType)
+	}
+}
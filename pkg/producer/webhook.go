@@ -0,0 +1,157 @@
+package producer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/stats"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Header with the HMAC-SHA256 signature of the body, when a secret is configured
+const signatureHeader = "X-Signature-256"
+
+// WebhookSink POSTs each event as JSON to the configured URL, {status} and {topic} are replaced in the URL
+// (bank updates use the "bank" status and the "banks" topic).
+// The 5xx responses, 429 and the connection errors are retried with exponential backoff.
+type WebhookSink struct {
+	config config.WebhookConfig
+	client *http.Client
+	sem    chan struct{} // Bounds the concurrent requests
+	stats  *stats.Stats
+}
+
+// Non 2xx response
+type webhookError struct {
+	url    string
+	status int
+}
+
+func (e *webhookError) Error() string {
+	return fmt.Sprintf("POST %s: %d %s", e.url, e.status, http.StatusText(e.status))
+}
+
+func (e *webhookError) retriable() bool {
+	return e.status >= 500 || e.status == http.StatusTooManyRequests
+}
+
+func NewWebhookSink(config config.Config, sts *stats.Stats) (*WebhookSink, error) {
+	cfg := config.Sink.Webhook
+	if _, err := url.Parse(webhookUrl(cfg.Url, "initiated", "payment-initiated")); err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	logger.With("url", cfg.Url).With("concurrency", cfg.Concurrency).Info("Using webhook: ")
+	return &WebhookSink{
+		config: cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, MaxIdleConnsPerHost: cfg.Concurrency},
+		},
+		sem:   make(chan struct{}, cfg.Concurrency),
+		stats: sts,
+	}, nil
+}
+
+func webhookUrl(template string, status string, topic string) string {
+	return strings.NewReplacer("{status}", url.PathEscape(status), "{topic}", url.PathEscape(topic)).Replace(template)
+}
+
+func (s *WebhookSink) Produce(ctx context.Context, payment model.Payment) error {
+	topic := config.PaymentTopic(payment.Status)
+	return s.send(ctx, topic, payment.Status, strings.ToLower(payment.Status), &payment)
+}
+
+func (s *WebhookSink) ProduceBank(ctx context.Context, bank model.Bank) error {
+	return s.send(ctx, "banks", "Bank", "bank", &bank)
+}
+
+func (s *WebhookSink) send(ctx context.Context, topic string, event string, status string, value json.Marshaler) error {
+	body, err := value.MarshalJSON()
+	if err == nil {
+		select {
+		case s.sem <- struct{}{}:
+			err = s.post(ctx, webhookUrl(s.config.Url, status, topic), topic, body)
+			<-s.sem
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) { // Abandoned, not delivered nor failed
+		return err
+	}
+	s.stats.AddDelivery(topic, event, err)
+	return err
+}
+
+// post sends the body, retrying the 5xx responses and the connection errors until the context is done
+func (s *WebhookSink) post(ctx context.Context, target string, topic string, body []byte) error {
+	backoff := s.config.Backoff
+	err := s.request(ctx, target, topic, body)
+	for retry := 0; err != nil && ctx.Err() == nil && retry < s.config.Retries; retry++ {
+		if werr, ok := err.(*webhookError); ok && !werr.retriable() {
+			break
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff *= 2
+		err = s.request(ctx, target, topic, body)
+	}
+	return err
+}
+
+func (s *WebhookSink) request(ctx context.Context, target string, topic string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Topic", topic)
+	for name, value := range s.config.Headers {
+		req.Header.Set(name, value)
+	}
+	if len(s.config.Secret) > 0 {
+		mac := hmac.New(sha256.New, []byte(s.config.Secret))
+		mac.Write(body)
+		req.Header.Set(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) // Reuses the connection
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &webhookError{url: target, status: resp.StatusCode}
+	}
+	return nil
+}
+
+// Flush is a no-op, the requests are sent synchronously
+func (s *WebhookSink) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (s *WebhookSink) Close() {
+	s.client.CloseIdleConnections()
+}
+
+// CreateTopics is a no-op, the endpoints must exist
+func (s *WebhookSink) CreateTopics() error {
+	return nil
+}
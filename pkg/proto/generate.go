@@ -0,0 +1,3 @@
+package proto
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=paths=source_relative payment.proto bank.proto
@@ -2,8 +2,12 @@ package stats
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 )
@@ -13,16 +17,92 @@ type Stats struct {
 	states    map[string]int
 	workflows map[string]int
 	banks     map[string]int
+	start     time.Time
+	end       time.Time
+	targets   map[string]float64
+	rates     map[string]*rate // by name, "Payments" or "Events"
+	errors    map[string]map[string]int
+	delivered map[string]*delivery // by status
+	topics    map[string]*delivery // by topic
+	delays    map[string]*latency  // sampled delays by status
+	rng       *rand.Rand           // reservoir sampling of the delays
 }
 
+// Delivery reports counters
+type delivery struct {
+	delivered int
+	failed    int
+}
+
+// Events counted for an achieved rate, between the first and the last one
+type rate struct {
+	count int
+	first time.Time
+	last  time.Time
+}
+
+// Sampled delays (milliseconds), the percentiles are computed on a uniform sample of up to maxSamples delays
+type latency struct {
+	count   int
+	sum     float64
+	min     float64
+	max     float64
+	samples []float64
+}
+
+const maxSamples = 10000
+
+// Sink error outcomes
+const (
+	Retried = "Retried"
+	Skipped = "Skipped"
+	Aborted = "Aborted"
+)
+
 func NewStats() *Stats {
 	s := Stats{}
 	s.states = make(map[string]int)
 	s.workflows = make(map[string]int)
 	s.banks = make(map[string]int)
+	s.targets = make(map[string]float64)
+	s.rates = make(map[string]*rate)
+	s.errors = make(map[string]map[string]int)
+	s.delivered = make(map[string]*delivery)
+	s.topics = make(map[string]*delivery)
+	s.delays = make(map[string]*latency)
+	s.rng = rand.New(rand.NewSource(1))
 	return &s
 }
 
+// Start sets the beginning of the run
+func (s *Stats) Start() {
+	s.start = time.Now()
+}
+
+// Stop sets the end of the run, including the drain of the in-flight workflows
+func (s *Stats) Stop() {
+	s.end = time.Now()
+}
+
+// SetTargetRate sets the target rate (per second) of "Payments" or "Events", 0 means unlimited
+func (s *Stats) SetTargetRate(name string, rate float64) {
+	s.targets[name] = rate
+}
+
+// AddRate counts an event of the "Payments" (workflow started) or "Events" (status update produced) rate
+func (s *Stats) AddRate(name string) {
+	now := time.Now()
+	s.sync.Lock()
+	defer s.sync.Unlock()
+	r, ok := s.rates[name]
+	if !ok {
+		r = &rate{first: now}
+		s.rates[name] = r
+	}
+	r.count += 1
+	r.last = now
+}
+
 func (s *Stats) AddState(state string) {
 	s.states[state] = 0
 }
@@ -39,6 +119,80 @@ func (s *Stats) AddWorkflow(workflow string) {
 	s.sync.Unlock()
 }
 
+// AddError counts a sink error for the event (status or bank) by outcome: Retried, Skipped or Aborted
+func (s *Stats) AddError(event string, outcome string) {
+	s.sync.Lock()
+	if s.errors[event] == nil {
+		s.errors[event] = make(map[string]int)
+	}
+	s.errors[event][outcome] += 1
+	s.sync.Unlock()
+}
+
+// Errors returns the number of events skipped or aborted because of sink errors
+func (s *Stats) Errors() int {
+	s.sync.Lock()
+	defer s.sync.Unlock()
+	total := 0
+	for _, outcomes := range s.errors {
+		total += outcomes[Skipped] + outcomes[Aborted]
+	}
+	return total
+}
+
+// AddDelivery counts the delivery report of an event (status or "Bank") produced to the topic, err is the delivery error
+func (s *Stats) AddDelivery(topic string, event string, err error) {
+	s.sync.Lock()
+	countDelivery(s.delivered, event, err)
+	countDelivery(s.topics, topic, err)
+	s.sync.Unlock()
+}
+
+func countDelivery(counters map[string]*delivery, key string, err error) {
+	d, ok := counters[key]
+	if !ok {
+		d = &delivery{}
+		counters[key] = d
+	}
+	if err != nil {
+		d.failed += 1
+	} else {
+		d.delivered += 1
+	}
+}
+
+// DeliveryFailures returns the number of failed delivery reports
+func (s *Stats) DeliveryFailures() int {
+	s.sync.Lock()
+	defer s.sync.Unlock()
+	total := 0
+	for _, d := range s.topics {
+		total += d.failed
+	}
+	return total
+}
+
+// AddDelay records the delay applied before the status update
+func (s *Stats) AddDelay(status string, delay time.Duration) {
+	ms := float64(delay) / float64(time.Millisecond)
+	s.sync.Lock()
+	defer s.sync.Unlock()
+	l, ok := s.delays[status]
+	if !ok {
+		l = &latency{min: ms, max: ms}
+		s.delays[status] = l
+	}
+	l.count += 1
+	l.sum += ms
+	l.min = math.Min(l.min, ms)
+	l.max = math.Max(l.max, ms)
+	if len(l.samples) < maxSamples {
+		l.samples = append(l.samples, ms)
+	} else if i := s.rng.Intn(l.count); i < maxSamples { // Reservoir sampling
+		l.samples[i] = ms
+	}
+}
+
 func (s *Stats) AddBank(bank string) {
 	s.sync.Lock()
 	s.banks[bank] += 1
@@ -48,22 +202,37 @@ func (s *Stats) AddBank(bank string) {
 func (s *Stats) Print() {
 	s.PrintWorkflows()
 	s.PrintStates()
+	s.PrintDelays()
+	s.PrintTopics()
 	s.PrintBanks()
+	s.PrintRates()
+	s.PrintErrors()
 	fmt.Println("\n ")
 }
 
+// PrintWorkflows prints the distribution of the workflows (status paths), most frequent first
 func (s *Stats) PrintWorkflows() {
 	fmt.Println("\n ")
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Workflow", "Count"})
+	t.AppendHeader(table.Row{"Workflow", "Count", "%"})
 	total := 0
+	var workflows []string
 	for workflow, count := range s.workflows {
-		t.AppendRow([]interface{}{workflow, count})
+		workflows = append(workflows, workflow)
 		total += count
 	}
+	sort.Slice(workflows, func(i, j int) bool {
+		if s.workflows[workflows[i]] != s.workflows[workflows[j]] {
+			return s.workflows[workflows[i]] > s.workflows[workflows[j]]
+		}
+		return workflows[i] < workflows[j]
+	})
+	for _, workflow := range workflows {
+		t.AppendRow([]interface{}{workflow, s.workflows[workflow], fmt.Sprintf("%.2f", 100*float64(s.workflows[workflow])/float64(total))})
+	}
 	t.AppendSeparator()
-	t.AppendFooter(table.Row{"Total", total})
+	t.AppendFooter(table.Row{"Total", total, ""})
 	t.Render()
 }
 
@@ -71,15 +240,72 @@ func (s *Stats) PrintStates() {
 	fmt.Println("\n ")
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Status", "Produced Events"})
-	total := 0
+	t.AppendHeader(table.Row{"Status", "Produced Events", "Delivered", "Failed"})
+	total, delivered, failed := 0, 0, 0
 
 	for state, count := range s.states {
-		t.AppendRow([]interface{}{state, count})
+		d := s.delivered[state]
+		if d == nil {
+			d = &delivery{}
+		}
+		t.AppendRow([]interface{}{state, count, d.delivered, d.failed})
 		total += count
+		delivered += d.delivered
+		failed += d.failed
+	}
+	t.AppendSeparator()
+	t.AppendFooter(table.Row{"Total", total, delivered, failed})
+	t.Render()
+}
+
+// PrintDelays prints the distribution of the sampled delays by status, in milliseconds
+func (s *Stats) PrintDelays() {
+	if len(s.delays) == 0 {
+		return
+	}
+	fmt.Println("\n ")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Delay (ms)", "Count", "Min", "Mean", "P50", "P90", "P99", "Max"})
+	var statuses []string
+	for status := range s.delays {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		l := s.delays[status]
+		samples := append([]float64{}, l.samples...)
+		sort.Float64s(samples)
+		t.AppendRow([]interface{}{status, l.count,
+			fmt.Sprintf("%.1f", l.min), fmt.Sprintf("%.1f", l.sum/float64(l.count)),
+			fmt.Sprintf("%.1f", percentile(samples, 50)), fmt.Sprintf("%.1f", percentile(samples, 90)),
+			fmt.Sprintf("%.1f", percentile(samples, 99)), fmt.Sprintf("%.1f", l.max)})
+	}
+	t.Render()
+}
+
+// percentile of the sorted samples, nearest rank
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+func (s *Stats) PrintTopics() {
+	fmt.Println("\n ")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Topic", "Delivered", "Failed"})
+	delivered, failed := 0, 0
+	for topic, d := range s.topics {
+		t.AppendRow([]interface{}{topic, d.delivered, d.failed})
+		delivered += d.delivered
+		failed += d.failed
 	}
 	t.AppendSeparator()
-	t.AppendFooter(table.Row{"Total", total})
+	t.AppendFooter(table.Row{"Total", delivered, failed})
 	t.Render()
 }
 
@@ -97,3 +323,57 @@ func (s *Stats) PrintBanks() {
 
 	t.Render()
 }
+
+// PrintRates prints the achieved rates, measured between the first and the last event of each rate,
+// so that the payments rate doesn't include the drain of the in-flight workflows
+func (s *Stats) PrintRates() {
+	fmt.Println("\n ")
+	end := s.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Rate", "Count", "Window", "Achieved (/s)", "Target (/s)"})
+	s.sync.Lock()
+	for _, name := range []string{"Payments", "Events"} {
+		r := s.rates[name]
+		if r == nil {
+			r = &rate{}
+		}
+		window := r.last.Sub(r.first).Seconds()
+		achieved := 0.0
+		if r.count > 1 && window > 0 { // Intervals between the events
+			achieved = float64(r.count-1) / window
+		}
+		target := "unlimited"
+		if s.targets[name] > 0 {
+			target = fmt.Sprintf("%.2f", s.targets[name])
+		}
+		t.AppendRow([]interface{}{name, r.count, fmt.Sprintf("%.2fs", window), fmt.Sprintf("%.2f", achieved), target})
+	}
+	s.sync.Unlock()
+	t.AppendSeparator()
+	t.AppendFooter(table.Row{"Elapsed", "", fmt.Sprintf("%.2fs", end.Sub(s.start).Seconds())})
+	t.Render()
+}
+
+func (s *Stats) PrintErrors() {
+	if len(s.errors) == 0 {
+		return
+	}
+	fmt.Println("\n ")
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Sink errors", Retried, Skipped, Aborted})
+	retried, skipped, aborted := 0, 0, 0
+	for event, outcomes := range s.errors {
+		t.AppendRow([]interface{}{event, outcomes[Retried], outcomes[Skipped], outcomes[Aborted]})
+		retried += outcomes[Retried]
+		skipped += outcomes[Skipped]
+		aborted += outcomes[Aborted]
+	}
+	t.AppendSeparator()
+	t.AppendFooter(table.Row{"Total", retried, skipped, aborted})
+	t.Render()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/producer"
+	"mcolomerc/synth-payment-producer/pkg/stats"
+	"sync"
+)
+
+// Cancel the run and the in-flight workflows, set by main
+var cancelRun context.CancelFunc
+var cancelInflight context.CancelFunc
+
+var abortMu sync.Mutex
+var abortErr error
+
+// Stops the run because of err, only the first error is kept
+func abort(err error) {
+	abortMu.Lock()
+	defer abortMu.Unlock()
+	if abortErr != nil {
+		return
+	}
+	logger.Error("## Aborting: %s ##", err)
+	abortErr = err
+	cancelRun()
+	cancelInflight()
+}
+
+// The error that aborted the run, nil if not aborted
+func aborted() error {
+	abortMu.Lock()
+	defer abortMu.Unlock()
+	return abortErr
+}
+
+/**
+ * Applies the error policy (ERROR_POLICY) to a sink call:
+ * retries the retriable errors with exponential backoff, skips the event or aborts the run.
+ * event is the status or bank name used in the stats. Returns false when the event was not sent.
+ */
+func send(ctx context.Context, event string, produce func() error) bool {
+	err := produce()
+	backoff := cnf.Errors.Backoff
+	for retry := 0; err != nil && cnf.Errors.Policy == config.ErrorRetry && producer.IsRetriable(err) && retry < cnf.Errors.Retries; retry++ {
+		sts.AddError(event, stats.Retried)
+		if !sleep(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+		err = produce()
+	}
+	if err == nil {
+		return true
+	}
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) { // Abandoned on shutdown, not a sink error
+		return false
+	}
+	if cnf.Errors.Policy == config.ErrorAbort {
+		sts.AddError(event, stats.Aborted)
+		abort(err)
+		return false
+	}
+	logger.Error("Skipping %s event: %s", event, err)
+	sts.AddError(event, stats.Skipped)
+	return false
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	model "mcolomerc/synth-payment-producer/pkg/avro"
+	"mcolomerc/synth-payment-producer/pkg/config"
+	"mcolomerc/synth-payment-producer/pkg/datagen"
+	"mcolomerc/synth-payment-producer/pkg/producer"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func newLimiter(perSecond float64) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), 1)
+}
+
+// Blocks until the limiter allows the next event, false if the context is done
+func wait(ctx context.Context, limiter *rate.Limiter) bool {
+	if limiter == nil {
+		return ctx.Err() == nil
+	}
+	return limiter.Wait(ctx) == nil
+}
+
+// Sleeps for the given delay, false if the context is done before
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Updates a random bank of the catalogue on each update, incrementing its version
+type bankUpdater struct {
+	banks []model.Bank
+	rng   *rand.Rand
+}
+
+func newBankUpdater() *bankUpdater {
+	return &bankUpdater{
+		banks: paymentGenerator.GetBanks(), // Get banks
+		rng:   rand.New(rand.NewSource(seed + 2)),
+	}
+}
+
+// Produces the update of a random bank, updated at ts
+func (b *bankUpdater) update(ctx context.Context, ts time.Time) {
+	logger.Info("## BANKS ## Updating bank ...")
+	randIdex := b.rng.Intn(len(b.banks))
+	bank := b.banks[randIdex]
+	bank.Updated_ts = ts.Format(time.RFC3339)
+	bank.Version += 1
+	b.banks[randIdex] = bank
+	logger.Info(" Bank: %v", bank)
+	if send(ctx, "Bank", func() error { return sink.ProduceBank(ctx, bank) }) {
+		sts.AddBank(bank.Name)
+	}
+}
+
+func buildBanks(ctx context.Context, ticker *time.Ticker) {
+	logger.Info("## BANKS ## Generating banks")
+	defer ticker.Stop()
+	updater := newBankUpdater()
+	for {
+		select {
+		case <-ticker.C:
+			updater.update(ctx, time.Now())
+		case <-ctx.Done():
+			logger.Info("## BANKS ## Done")
+			return
+		}
+	}
+}
+
+var switchSchemaOnce sync.Once
+
+// Switches the sink to the schema.switchTo version (schema evolution), the next events use the new schema
+func switchSchema(reason string) {
+	versioned, ok := sink.(producer.SchemaVersioned)
+	if !ok || cnf.Schema.SwitchTo == 0 {
+		return
+	}
+	switchSchemaOnce.Do(func() {
+		logger.Info("## Schema evolution: switching from v%v to v%v, %s ##", cnf.Schema.Version, cnf.Schema.SwitchTo, reason)
+		versioned.SetSchemaVersion(cnf.Schema.SwitchTo)
+	})
+}
+
+/**
+ * Generates payments until NUM_PAYMENTS is reached (count mode), the end of the backfill window is reached (backfill mode)
+ * or forever, and stops when the context is done (shutdown, or deadline in duration mode).
+ * The payments channel is closed when the generation is done.
+ * Blocks while the channel is full, so the generation follows the pace of the workers and the sink.
+ */
+func generate(ctx context.Context, paymentsCh chan<- job) {
+	defer close(paymentsCh)
+	var clock *simulatedClock
+	if cnf.Datagen.Mode == config.ModeBackfill {
+		clock = newSimulatedClock(cnf.Datagen.Backfill, time.Duration(cnf.Datagen.UpdateBanksInterval)*time.Millisecond)
+	}
+	for i := 0; cnf.Datagen.Mode != config.ModeCount || i < numPayments; i++ {
+		if cnf.Schema.SwitchAfterPayments > 0 && i == cnf.Schema.SwitchAfterPayments {
+			switchSchema(fmt.Sprintf("%v payments generated", i))
+		}
+		var start time.Time // Simulated start of the payment, zero with the real clock
+		if clock != nil {
+			var ok bool
+			if start, ok = clock.next(ctx); !ok {
+				logger.Info("## Backfill window done, %v payments generated ##", i)
+				return
+			}
+		}
+		logger.Info(" Generating payment...%v", i)
+		payment := paymentGenerator.GeneratePayment() // Generate payment
+		wk := workflowHandler.GetWorkflow()           // Pick workflow
+		select {
+		case paymentsCh <- job{payment: payment, workflow: wk, delays: delays.Sample(wk), start: start}:
+		case <-ctx.Done():
+			logger.Info("## Generation stopped, %v payments generated ##", i)
+			return
+		}
+	}
+}
+
+/**
+ * Worker: takes payments until the channel is closed or the context is done.
+ * The status updates of the in-flight workflow are abandoned when the inflight context is done.
+ */
+func worker(ctx context.Context, inflight context.Context, w int, paymentsCh <-chan job) {
+	for {
+		var j job
+		var ok bool
+		select {
+		case j, ok = <-paymentsCh:
+			if !ok {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+		if ctx.Err() != nil { // Buffered payments are not started
+			return
+		}
+		payment, wk := j.payment, j.workflow
+		if !wait(ctx, paymentsLimiter) {
+			return
+		}
+		sts.AddRate("Payments")
+		logger.Info(" Worker-%v : Producing payment: %v : Workflow: %v", w, payment, wk)
+		completed := true
+		if !j.start.IsZero() {
+			completed = produceSimulated(inflight, w, j)
+		} else if cnf.Datagen.Sequential {
+			for i := range wk {
+				if payment, completed = produceStatus(inflight, w, payment, wk[i], j.delays[i], time.Time{}); !completed { // Waits for the previous status
+					break
+				}
+			}
+		} else {
+			// Get workflow status
+			var statusDone sync.WaitGroup
+			produced := make([]bool, len(wk))
+			for i := range wk {
+				statusDone.Add(1)
+				go func(i int, payment model.Payment) {
+					defer statusDone.Done()
+					_, produced[i] = produceStatus(inflight, w, payment, wk[i], j.delays[i], time.Time{})
+				}(i, payment)
+			}
+			statusDone.Wait()
+			for i := range produced {
+				completed = completed && produced[i]
+			}
+		}
+		if !completed && inflight.Err() != nil {
+			logger.Info(" Worker-%v : Abandoned payment workflow: %v : Workflow: %v", w, payment.Id, wk)
+			return
+		}
+		if !completed { // Not counted, an event was skipped
+			logger.Info(" Worker-%v : Incomplete payment workflow: %v : Workflow: %v", w, payment.Id, wk)
+			continue
+		}
+		sts.AddWorkflow(fmt.Sprintf("%v", wk)) // Add workflow
+	}
+}
+
+/**
+ * Produces the status updates of a backfill payment without delays, timestamped with the simulated clock:
+ * each delay is applied from the payment start, or from the previous status in sequential mode.
+ * The updates are produced in timestamp order, false if abandoned (context done) or an update was skipped.
+ */
+func produceSimulated(ctx context.Context, w int, j job) bool {
+	at := make([]time.Time, len(j.workflow))
+	order := make([]int, len(j.workflow))
+	ts := j.start
+	for i, delay := range j.delays {
+		if cnf.Datagen.Sequential {
+			ts = ts.Add(delay)
+			at[i] = ts
+		} else {
+			at[i] = j.start.Add(delay)
+		}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return at[order[a]].Before(at[order[b]]) })
+	payment := j.payment
+	for _, i := range order {
+		var produced bool
+		if payment, produced = produceStatus(ctx, w, payment, j.workflow[i], j.delays[i], at[i]); !produced {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * Applies the sampled status delay and produces the payment status update, false if abandoned (context done) or skipped (sink error).
+ * With a simulated timestamp (at), the delay is already applied to at.
+ */
+func produceStatus(ctx context.Context, w int, payment model.Payment, status datagen.Status, delay time.Duration, at time.Time) (model.Payment, bool) {
+	payment.Status = status.String()
+	simulated := !at.IsZero()
+	if !simulated && !sleep(ctx, delay) { // Apply delay
+		return payment, false
+	}
+	if !wait(ctx, eventsLimiter) {
+		return payment, false
+	}
+	if !simulated {
+		at = time.Now() // Once the limiter allows the event
+	}
+	sts.AddDelay(payment.Status, delay)
+	payment.Ts = at.UTC().UnixNano() / 1000000
+	payment.Date_ts = at.Format(time.RFC3339)
+	logger.Info("\t Worker-%v : Producing payment status update: %v ", w, payment)
+	if !send(ctx, payment.Status, func() error { return sink.Produce(ctx, payment) }) {
+		return payment, false
+	}
+	sts.IncState(payment.Status) // Increment state counter
+	sts.AddRate("Events")
+	return payment, true
+}